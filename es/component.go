@@ -1,4 +1,339 @@
 package es
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/nextmicro/logger"
+	"github.com/nextmicro/next-component/es/middleware"
+	"github.com/nextmicro/next-component/es/middleware/logging"
+	"github.com/nextmicro/next-component/es/middleware/metrics"
+	"github.com/nextmicro/next-component/internal/connect"
+	"github.com/nextmicro/next/config"
+	"github.com/nextmicro/next/runtime/loader"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	Elasticsearch *Component
+	_             loader.Loader = &Component{}
+
+	// ErrInstanceNotFound 命名实例不存在
+	ErrInstanceNotFound = errors.New("instance not found")
+	// ErrNotConnected 延迟连接或非关键实例尚未建立连接
+	ErrNotConnected = errors.New("instance not connected")
+)
+
 type Component struct {
+	open    bool
+	options []Option
+	opts    map[string]*Options
+	group   singleflight.Group
+	lazy    connect.Lazy // 延迟连接与非关键实例最近一次连接失败的原因
+	clients sync.Map
+	// transports 实例名对应的底层传输，Stop 时关闭其空闲连接
+	transports sync.Map
+}
+
+// idleCloser 可关闭空闲连接的传输，例如 *http.Transport
+type idleCloser interface {
+	CloseIdleConnections()
+}
+
+func New(options ...Option) *Component {
+	Elasticsearch = &Component{
+		options: options,
+		opts:    make(map[string]*Options),
+	}
+	return Elasticsearch
+}
+
+// Init 初始化
+func (c *Component) Init(opts ...loader.Option) error {
+	err := config.Value(namespace).Scan(&c.opts)
+	if err != nil {
+		return fmt.Errorf("es: %s", err)
+	}
+
+	op := loader.Options{}
+	for _, opt := range opts {
+		opt(&op)
+	}
+
+	if op.Context != nil {
+		cfg, ok := op.Context.Value(esConfig{}).(Options)
+		if !ok {
+			return errors.New("es: config not found")
+		}
+
+		for _, option := range cfg.Options() {
+			for _, opt := range c.opts {
+				option.apply(opt)
+			}
+		}
+	}
+
+	for _, option := range c.options {
+		for _, opt := range c.opts {
+			option.apply(opt)
+		}
+	}
+
+	if len(c.opts) == 0 {
+		return nil
+	}
+
+	for name, opt := range c.opts {
+		if opt.Lazy {
+			continue
+		}
+
+		client, err := c.connect(name, opt)
+		if err != nil {
+			if opt.Optional {
+				logger.Errorf("es: optional instance %s connect error: %v", name, err)
+				c.lazy.Fail(name, err)
+				continue
+			}
+			return err
+		}
+
+		c.clients.Store(name, client)
+	}
+
+	c.open = true
+	logger.Infof("Component [%s] Init success", c.String())
+	return nil
+}
+
+// Get 获取命名实例，name 为空时返回 default 实例，实例不存在时返回 ErrInstanceNotFound
+func (c *Component) Get(name string) (*elasticsearch.Client, error) {
+	if name == "" {
+		name = defaultName
+	}
+
+	value, ok := c.clients.Load(name)
+	if !ok {
+		return c.lazyConnect(name)
+	}
+
+	return value.(*elasticsearch.Client), nil
+}
+
+// lazyConnect 为延迟连接或启动时连接失败的非关键实例按需建立连接，
+// 通过 singleflight 保证同一实例并发获取时只建立一次连接。按需连接只尝试1次且限制时长，
+// 失败后在冷却期内直接返回上次的错误，避免请求阻塞在启动重试上。
+func (c *Component) lazyConnect(name string) (*elasticsearch.Client, error) {
+	opt, ok := c.opts[name]
+	if !ok || !(opt.Lazy || opt.Optional) {
+		return nil, fmt.Errorf("es: %w, group: %s", ErrInstanceNotFound, name)
+	}
+	if err := c.lazy.Err(name); err != nil {
+		return nil, err
+	}
+
+	value, err, _ := c.group.Do(name, func() (interface{}, error) {
+		if value, ok := c.clients.Load(name); ok {
+			return value, nil
+		}
+
+		cfg := *opt
+		cfg.ConnectMaxAttempts, cfg.ConnectTimeout = 1, connect.OnceTimeout(opt.ConnectTimeout)
+		client, err := c.connect(name, &cfg)
+		if err != nil {
+			c.lazy.Fail(name, err)
+			return nil, err
+		}
+
+		c.lazy.Reset(name)
+
+		c.clients.Store(name, client)
+		return client, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(*elasticsearch.Client), nil
+}
+
+// MustGet 获取命名实例，实例不存在时 panic
+func (c *Component) MustGet(name string) *elasticsearch.Client {
+	client, err := c.Get(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return client
+}
+
+// Instance 获取命名实例，等同于 MustGet，未传 name 时返回 default 实例
+func (c *Component) Instance(name ...string) *elasticsearch.Client {
+	var group string
+	if len(name) > 0 {
+		group = name[0]
+	}
+
+	return c.MustGet(group)
+}
+
+// Health 检查每个命名实例当前是否可用，尚未建立连接的实例返回包装 ErrNotConnected 的错误，
+// 其中非关键实例与尚未使用的延迟连接实例报告为 DEGRADED
+func (c *Component) Health(ctx context.Context) map[string]error {
+	ret := make(map[string]error)
+	c.clients.Range(func(key, value interface{}) bool {
+		ret[key.(string)] = ping(ctx, value.(*elasticsearch.Client))
+		return true
+	})
+
+	for name, opt := range c.opts {
+		if _, ok := ret[name]; !ok {
+			ret[name] = c.lazy.Health(name, opt.Optional, fmt.Errorf("es: %w, group: %s", ErrNotConnected, name))
+		}
+	}
+	return ret
+}
+
+func ping(ctx context.Context, client *elasticsearch.Client) error {
+	res, err := client.Ping(client.Ping.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return fmt.Errorf("es: ping %s", res.Status())
+	}
+	return nil
+}
+
+func (c *Component) connect(name string, cfg *Options) (*elasticsearch.Client, error) {
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+	if cfg.MaxIdleConnsPerHost == 0 {
+		cfg.MaxIdleConnsPerHost = 10
+	}
+	if cfg.IdleConnTimeout == 0 {
+		cfg.IdleConnTimeout = 90 * time.Second
+	}
+	if cfg.SlowThreshold == 0 {
+		cfg.SlowThreshold = time.Millisecond * 100
+	}
+
+	var transport = cfg.Transport
+	if transport == nil {
+		transport = &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   cfg.DialTimeout,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+			IdleConnTimeout:       cfg.IdleConnTimeout,
+			ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		}
+	}
+
+	base := transport
+	ms := make([]middleware.Middleware, 0, 2)
+
+	// metrics
+	if !cfg.DisableMetric {
+		ms = append(ms, metrics.Client(
+			metrics.WithName(name),
+			metrics.WithAddr(strings.Join(cfg.Addresses, ",")),
+			metrics.WithDisabled(cfg.DisableMetric)),
+		)
+	}
+
+	// logging
+	if !cfg.DisableLogging {
+		ms = append(ms, logging.Client(
+			logging.WithRequest(cfg.EnableLoggingRequest),
+			logging.WithResponse(cfg.EnableLoggingResponse),
+			logging.WithDisabled(cfg.DisableLogging),
+			logging.WithSlowThreshold(cfg.SlowThreshold),
+		))
+	}
+
+	ms = append(ms, cfg.Middlewares...)
+	transport = middleware.Chain(ms...)(transport)
+
+	// tracing
+	if !cfg.DisableTrace {
+		transport = otelhttp.NewTransport(transport)
+	}
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{
+		Addresses:  cfg.Addresses,
+		Username:   cfg.Username,
+		Password:   cfg.Password,
+		APIKey:     cfg.APIKey,
+		CloudID:    cfg.CloudID,
+		MaxRetries: cfg.MaxRetries,
+		Transport:  transport,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = retry(name, cfg, func(ctx context.Context) error {
+		return ping(ctx, client)
+	})
+	if err != nil {
+		closeIdle(base)
+		return nil, err
+	}
+	c.transports.Store(name, base)
+
+	logger.Infof("%s %s connected success", namespace, name)
+	return client, nil
+}
+
+func (c *Component) Open() bool {
+	return c.open
+}
+
+func (c *Component) Start(ctx context.Context) error {
+	logger.Infof("Component [%s] Start success", c.String())
+	return nil
+}
+
+func (c *Component) Watch() error {
+	return nil
+}
+
+func (c *Component) Stop(ctx context.Context) error {
+	c.clients.Range(func(key, value interface{}) bool {
+		if transport, ok := c.transports.LoadAndDelete(key); ok {
+			closeIdle(transport)
+		}
+		c.clients.Delete(key)
+		return true
+	})
+
+	logger.Infof("Component [%s] stop success", c.String())
+	return nil
+}
+
+func (c *Component) String() string {
+	return namespace
+}
+
+// closeIdle 关闭传输上的空闲连接，不支持的传输忽略
+func closeIdle(transport interface{}) {
+	if closer, ok := transport.(idleCloser); ok {
+		closer.CloseIdleConnections()
+	}
 }
@@ -0,0 +1,206 @@
+package es_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	kconfig "github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+	"github.com/nextmicro/next-component/es"
+	"github.com/nextmicro/next/config"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"cluster_name":"test","version":{"number":"8.11.1"}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func loadConfig(t *testing.T, content string) {
+	filename := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := kconfig.New(kconfig.WithSource(file.NewSource(filename)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	config.DefaultConfig = c
+	t.Cleanup(func() { _ = c.Close() })
+}
+
+func TestComponent(t *testing.T) {
+	srv := newServer(t)
+	loadConfig(t, fmt.Sprintf(`{"es":{"default":{"addresses":["%s"]},"logs":{"addresses":["%s"]}}}`, srv.URL, srv.URL))
+
+	c := es.New(es.WithDisableTrace())
+	if err := c.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Open() {
+		t.Fatal("component not open")
+	}
+
+	for _, name := range []string{"", "logs"} {
+		res, err := c.Instance(name).Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = res.Body.Close()
+		if res.IsError() {
+			t.Fatalf("unexpected status: %s", res.Status())
+		}
+	}
+
+	if _, err := c.Get("missing"); !errors.Is(err, es.ErrInstanceNotFound) {
+		t.Fatalf("expected ErrInstanceNotFound, got %v", err)
+	}
+
+	for name, err := range c.Health(context.Background()) {
+		if err != nil {
+			t.Fatalf("instance %s unhealthy: %v", name, err)
+		}
+	}
+
+	if err := c.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestComponent_InitPingError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(srv.Close)
+	loadConfig(t, fmt.Sprintf(`{"es":{"default":{"addresses":["%s"]}}}`, srv.URL))
+
+	if err := es.New().Init(); err == nil {
+		t.Fatal("expected ping error")
+	}
+}
+
+func TestComponent_LazyAndOptional(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	}))
+	t.Cleanup(srv.Close)
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	loadConfig(t, fmt.Sprintf(`{"es":{"default":{"addresses":["%s"],"lazy":true},"backup":{"addresses":["%s"],"optional":true,"max_retries":1}}}`, srv.URL, down.URL))
+
+	c := es.New(es.WithDisableTrace())
+	if err := c.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Fatalf("lazy instance connected on init, requests = %d", n)
+	}
+
+	// 未连接的实例报告为 DEGRADED
+	for name, err := range c.Health(context.Background()) {
+		var d interface{ Degraded() bool }
+		if !errors.Is(err, es.ErrNotConnected) || !errors.As(err, &d) || !d.Degraded() {
+			t.Fatalf("health %s = %v, want degraded not connected", name, err)
+		}
+	}
+
+	if _, err := c.Get(""); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&requests); n == 0 {
+		t.Fatal("lazy instance not connected on first get")
+	}
+	if err := c.Health(context.Background())["default"]; err != nil {
+		t.Fatalf("health default = %v, want nil after connect", err)
+	}
+
+	_, err := c.Get("backup")
+	if err == nil || errors.Is(err, es.ErrInstanceNotFound) {
+		t.Fatalf("expected connect error, got %v", err)
+	}
+	// 冷却期内直接返回上次的错误
+	if _, again := c.Get("backup"); again == nil || again.Error() != err.Error() {
+		t.Fatalf("get in cooldown = %v, want %v", again, err)
+	}
+}
+
+func TestComponent_ConnectRetry(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	loadConfig(t, fmt.Sprintf(`{"es":{"default":{"addresses":["%s"],"connect_max_attempts":3,"connect_min_backoff":1000000}}}`, srv.URL))
+	if err := es.New(es.WithDisableTrace()).Init(); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 3 {
+		t.Fatalf("requests = %d, want 3", n)
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	loadConfig(t, fmt.Sprintf(`{"es":{"default":{"addresses":["%s"],"connect_max_attempts":2,"connect_min_backoff":1000000}}}`, srv.URL))
+	if err := es.New(es.WithDisableTrace()).Init(); err == nil {
+		t.Fatal("expected connect error after max attempts")
+	}
+}
+
+func TestComponent_StopClosesIdleConnections(t *testing.T) {
+	var closed int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			atomic.AddInt32(&closed, 1)
+		}
+	}
+	srv.Start()
+	t.Cleanup(srv.Close)
+	loadConfig(t, fmt.Sprintf(`{"es":{"default":{"addresses":["%s"]},"logs":{"addresses":["%s"]}}}`, srv.URL, srv.URL))
+
+	c := es.New(es.WithDisableTrace())
+	if err := c.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&closed); n != 0 {
+		t.Fatalf("closed connections = %d before Stop, want 0", n)
+	}
+
+	// 每个实例的空闲连接都被关闭
+	if err := c.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&closed) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("closed connections = %d after Stop, want 2", atomic.LoadInt32(&closed))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, err := c.Get(""); !errors.Is(err, es.ErrInstanceNotFound) {
+		t.Fatalf("get after Stop = %v, want %v", err, es.ErrInstanceNotFound)
+	}
+}
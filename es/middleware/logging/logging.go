@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/nextmicro/gokit/timex"
+	"github.com/nextmicro/logger"
+	"github.com/nextmicro/next-component/es/middleware"
+)
+
+func Client(opts ...Option) middleware.Middleware {
+	op := &options{
+		SlowThreshold: 100 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if op.disabled {
+				return next.RoundTrip(req)
+			}
+
+			var request []byte
+			if op.request && req.Body != nil && req.Body != http.NoBody {
+				request, req.Body = drain(req.Body)
+			}
+
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			duration := time.Since(start)
+
+			fields := map[string]interface{}{
+				"kind":      "db",
+				"component": component,
+				"method":    middleware.Command(req),
+				"statement": req.Method + " " + req.URL.RequestURI(),
+				"duration":  timex.Duration(duration),
+			}
+			if request != nil {
+				fields["request"] = string(request)
+			}
+			if resp != nil {
+				fields["status"] = resp.StatusCode
+				if op.response && resp.Body != nil {
+					var response []byte
+					response, resp.Body = drain(resp.Body)
+					fields["response"] = string(response)
+				}
+			}
+			if err != nil {
+				fields["error"] = err
+			}
+
+			log := logger.WithContext(req.Context()).WithFields(fields)
+			if duration > op.SlowThreshold {
+				log.Warn("elasticsearch client slow")
+			}
+
+			if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+				log.Error("elasticsearch client")
+			} else {
+				log.Info("elasticsearch client")
+			}
+
+			return resp, err
+		})
+	}
+}
+
+// drain reads all of b to memory and then returns an equivalent ReadCloser yielding the same bytes.
+func drain(b io.ReadCloser) ([]byte, io.ReadCloser) {
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(b); err != nil {
+		return nil, b
+	}
+	_ = b.Close()
+	return buf.Bytes(), io.NopCloser(bytes.NewReader(buf.Bytes()))
+}
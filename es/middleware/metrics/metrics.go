@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	prom "github.com/go-kratos/kratos/contrib/metrics/prometheus/v2"
+	"github.com/nextmicro/next-component/es/middleware"
+	"github.com/nextmicro/next/pkg/metrics"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Client elasticsearch metrics.
+func Client(opts ...Option) middleware.Middleware {
+	op := &options{
+		requests: prom.NewCounter(metrics.DBSystemMetricRequests),
+		seconds:  prom.NewHistogram(metrics.DBSystemMetricMillisecond),
+	}
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if op.disabled {
+				return next.RoundTrip(req)
+			}
+
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			var code = codes.Ok
+			if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+				code = codes.Error
+			}
+
+			cmd := middleware.Command(req)
+			op.requests.With(component, op.name, op.addr, cmd, code.String()).Inc()
+			op.seconds.With(component, op.name, op.addr, cmd).Observe(float64(time.Since(start).Milliseconds()))
+
+			return resp, err
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RoundTripperFunc is an adapter to allow the use of ordinary functions as http.RoundTripper.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls fn(req).
+func (fn RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+// Middleware is elasticsearch transport middleware.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// Chain returns a Middleware that specifies the chained handler for transport.
+func Chain(m ...Middleware) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		for i := len(m) - 1; i >= 0; i-- {
+			next = m[i](next)
+		}
+		return next
+	}
+}
+
+// Command returns the elasticsearch api name of the request, e.g. _search, _bulk, _doc.
+// If the path contains no api segment, the lower case http method is returned.
+func Command(req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if strings.HasPrefix(segments[i], "_") {
+			return segments[i]
+		}
+	}
+	return strings.ToLower(req.Method)
+}
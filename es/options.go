@@ -0,0 +1,304 @@
+package es
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/nextmicro/next-component/es/middleware"
+	"github.com/nextmicro/next/runtime/loader"
+)
+
+//{
+//    "es": {
+//        "default": {
+//            "addresses": ["http://127.0.0.1:9200"],
+//            "username": "elastic",
+//            "password": "changeme",
+//            "max_retries": 3
+//        }
+//    }
+//}
+
+const (
+	namespace   = "es"
+	defaultName = "default"
+)
+
+type Option interface {
+	apply(*Options)
+}
+
+type OptionFunc func(*Options)
+
+func (fn OptionFunc) apply(cfg *Options) {
+	fn(cfg)
+}
+
+type Options struct {
+	Addresses             []string                `json:"addresses"`               // 节点地址列表
+	Username              string                  `json:"username"`                // 用户名
+	Password              string                  `json:"password"`                // 密码
+	APIKey                string                  `json:"api_key"`                 // API Key，设置后优先于用户名密码
+	CloudID               string                  `json:"cloud_id"`                // Elastic Cloud ID
+	MaxRetries            int                     `json:"max_retries"`             // 最大重试次数，默认3次
+	DialTimeout           time.Duration           `json:"dial_timeout"`            // DialTimeout 拨号超时时间，默认5s
+	ResponseHeaderTimeout time.Duration           `json:"response_header_timeout"` // 等待响应头超时时间
+	MaxIdleConnsPerHost   int                     `json:"max_idle_conns_per_host"` // 每个节点最大空闲连接数，默认10
+	IdleConnTimeout       time.Duration           `json:"idle_conn_timeout"`       // 连接最大空闲时间，默认90s
+	SlowThreshold         time.Duration           `json:"slow_threshold"`          // 慢日志门限值，超过该门限值的请求，将被记录到慢日志中
+	Lazy                  bool                    `json:"lazy"`                    // 延迟连接，首次获取实例时才建立连接
+	Optional              bool                    `json:"optional"`                // 非关键实例，启动时连接失败仅记录日志，不影响组件初始化
+	DisableMetric         bool                    `json:"disable_metric"`          // 是否禁用监控，默认开启
+	DisableTrace          bool                    `json:"disable_trace"`           // 是否禁用链路追踪，默认开启
+	DisableLogging        bool                    `json:"disable_logging"`         // 是否禁用，记录请求数据
+	EnableLoggingRequest  bool                    `json:"enable_logging_request"`  // 是否开启记录请求参数
+	EnableLoggingResponse bool                    `json:"enable_logging_response"` // 是否开启记录响应参数
+	Transport             http.RoundTripper       `json:"-"`                       // 自定义底层传输
+	Middlewares           []middleware.Middleware `json:"-"`                       // 中间件
+
+	ConnectMaxAttempts int           `json:"connect_max_attempts"` // 建立连接的最大尝试次数，默认1次即不重试
+	ConnectMinBackoff  time.Duration `json:"connect_min_backoff"`  // 建立连接重试的初始间隔，默认1s，每次翻倍
+	ConnectMaxBackoff  time.Duration `json:"connect_max_backoff"`  // 建立连接重试的最大间隔，默认10s
+	ConnectTimeout     time.Duration `json:"connect_timeout"`      // 建立连接（含重试）的总时长，默认不限制
+}
+
+type esConfig struct{}
+
+// WithConfig sets the elasticsearch config
+func WithConfig(cfg Options) loader.Option {
+	return func(o *loader.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, esConfig{}, cfg)
+	}
+}
+
+// Options returns the elasticsearch config.
+func (o *Options) Options() []Option {
+	opts := make([]Option, 0, 10)
+	if len(o.Addresses) > 0 {
+		opts = append(opts, WithAddresses(o.Addresses))
+	}
+	if o.Username != "" {
+		opts = append(opts, WithUsername(o.Username))
+	}
+	if o.Password != "" {
+		opts = append(opts, WithPassword(o.Password))
+	}
+	if o.APIKey != "" {
+		opts = append(opts, WithAPIKey(o.APIKey))
+	}
+	if o.CloudID != "" {
+		opts = append(opts, WithCloudID(o.CloudID))
+	}
+	if o.MaxRetries != 0 {
+		opts = append(opts, WithMaxRetries(o.MaxRetries))
+	}
+	if o.DialTimeout != 0 {
+		opts = append(opts, WithDialTimeout(o.DialTimeout))
+	}
+	if o.ResponseHeaderTimeout != 0 {
+		opts = append(opts, WithResponseHeaderTimeout(o.ResponseHeaderTimeout))
+	}
+	if o.MaxIdleConnsPerHost != 0 {
+		opts = append(opts, WithMaxIdleConnsPerHost(o.MaxIdleConnsPerHost))
+	}
+	if o.IdleConnTimeout != 0 {
+		opts = append(opts, WithIdleConnTimeout(o.IdleConnTimeout))
+	}
+	if o.SlowThreshold != 0 {
+		opts = append(opts, WithSlowThreshold(o.SlowThreshold))
+	}
+	if o.Lazy {
+		opts = append(opts, WithLazy())
+	}
+	if o.Optional {
+		opts = append(opts, WithOptional())
+	}
+	if o.ConnectMaxAttempts != 0 || o.ConnectMinBackoff != 0 || o.ConnectMaxBackoff != 0 {
+		opts = append(opts, WithConnectRetry(o.ConnectMaxAttempts, o.ConnectMinBackoff, o.ConnectMaxBackoff))
+	}
+	if o.ConnectTimeout != 0 {
+		opts = append(opts, WithConnectTimeout(o.ConnectTimeout))
+	}
+	if o.DisableMetric {
+		opts = append(opts, WithDisableMetric())
+	}
+	if o.DisableTrace {
+		opts = append(opts, WithDisableTrace())
+	}
+	if o.DisableLogging {
+		opts = append(opts, WithDisableLogging())
+	}
+	if o.EnableLoggingRequest {
+		opts = append(opts, WithEnableLoggingRequest())
+	}
+	if o.EnableLoggingResponse {
+		opts = append(opts, WithEnableLoggingResponse())
+	}
+	if o.Transport != nil {
+		opts = append(opts, WithTransport(o.Transport))
+	}
+	if len(o.Middlewares) > 0 {
+		opts = append(opts, Middleware(o.Middlewares...))
+	}
+	return opts
+}
+
+// WithAddresses 设置节点地址
+func WithAddresses(addresses []string) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Addresses = addresses
+	})
+}
+
+// WithUsername 设置用户名
+func WithUsername(username string) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Username = username
+	})
+}
+
+// WithPassword 设置密码
+func WithPassword(password string) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Password = password
+	})
+}
+
+// WithAPIKey 设置 API Key
+func WithAPIKey(apiKey string) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.APIKey = apiKey
+	})
+}
+
+// WithCloudID 设置 Elastic Cloud ID
+func WithCloudID(cloudID string) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.CloudID = cloudID
+	})
+}
+
+// WithMaxRetries 设置最大重试次数
+func WithMaxRetries(maxRetries int) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.MaxRetries = maxRetries
+	})
+}
+
+// WithDialTimeout 设置拨号超时时间
+func WithDialTimeout(dialTimeout time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.DialTimeout = dialTimeout
+	})
+}
+
+// WithResponseHeaderTimeout 设置等待响应头超时时间
+func WithResponseHeaderTimeout(timeout time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.ResponseHeaderTimeout = timeout
+	})
+}
+
+// WithMaxIdleConnsPerHost 设置每个节点最大空闲连接数
+func WithMaxIdleConnsPerHost(n int) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.MaxIdleConnsPerHost = n
+	})
+}
+
+// WithIdleConnTimeout 设置连接最大空闲时间
+func WithIdleConnTimeout(timeout time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.IdleConnTimeout = timeout
+	})
+}
+
+// WithSlowThreshold 设置慢日志门限值
+func WithSlowThreshold(slowThreshold time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.SlowThreshold = slowThreshold
+	})
+}
+
+// WithLazy 设置延迟连接
+func WithLazy() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Lazy = true
+	})
+}
+
+// WithOptional 设置为非关键实例
+func WithOptional() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Optional = true
+	})
+}
+
+// WithConnectRetry 设置建立连接的最大尝试次数与重试间隔
+func WithConnectRetry(maxAttempts int, minBackoff, maxBackoff time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.ConnectMaxAttempts = maxAttempts
+		cfg.ConnectMinBackoff = minBackoff
+		cfg.ConnectMaxBackoff = maxBackoff
+	})
+}
+
+// WithConnectTimeout 设置建立连接（含重试）的总时长
+func WithConnectTimeout(timeout time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.ConnectTimeout = timeout
+	})
+}
+
+// WithDisableMetric 设置禁用监控
+func WithDisableMetric() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.DisableMetric = true
+	})
+}
+
+// WithDisableTrace 设置禁用链路
+func WithDisableTrace() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.DisableTrace = true
+	})
+}
+
+// WithDisableLogging 设置禁用日志
+func WithDisableLogging() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.DisableLogging = true
+	})
+}
+
+// WithEnableLoggingRequest 设置开启记录请求参数
+func WithEnableLoggingRequest() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.EnableLoggingRequest = true
+	})
+}
+
+// WithEnableLoggingResponse 设置开启记录响应参数
+func WithEnableLoggingResponse() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.EnableLoggingResponse = true
+	})
+}
+
+// WithTransport 设置自定义底层传输
+func WithTransport(transport http.RoundTripper) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Transport = transport
+	})
+}
+
+// Middleware with elasticsearch middleware option.
+func Middleware(m ...middleware.Middleware) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Middlewares = append(cfg.Middlewares, m...)
+	})
+}
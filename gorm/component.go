@@ -4,17 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 	"time"
 
-	"database/sql"
-
+	kconfig "github.com/go-kratos/kratos/v2/config"
 	"github.com/nextmicro/logger"
 	"github.com/nextmicro/next-component/gorm/plugin/logging"
 	"github.com/nextmicro/next-component/gorm/plugin/metrics"
+	"github.com/nextmicro/next-component/internal/connect"
 	"github.com/nextmicro/next/config"
 	"github.com/nextmicro/next/runtime/loader"
 	"github.com/uptrace/opentelemetry-go-extra/otelgorm"
+	"golang.org/x/sync/singleflight"
 	"gorm.io/driver/mysql" //golint
 	"gorm.io/gorm"
 	glogger "gorm.io/gorm/logger"
@@ -24,6 +26,11 @@ import (
 var (
 	Gorm *Component
 	_    loader.Loader = &Component{}
+
+	// ErrInstanceNotFound 命名实例不存在
+	ErrInstanceNotFound = errors.New("instance not found")
+	// ErrNotConnected 延迟连接或非关键实例尚未建立连接
+	ErrNotConnected = errors.New("instance not connected")
 )
 
 type Component struct {
@@ -31,8 +38,14 @@ type Component struct {
 	cancelFn func()
 	init     bool
 	options  []Option
+	mu       sync.Mutex
+	reloadMu sync.Mutex
 	opts     map[string]*Options
+	cancels  map[string]context.CancelFunc
+	group    singleflight.Group
+	lazy     connect.Lazy // 延迟连接与非关键实例最近一次连接失败的原因
 	clients  sync.Map
+	drains   sync.Map // 热更新后等待关闭的旧连接池 -> *time.Timer
 }
 
 // New creates mysql a new component
@@ -43,6 +56,7 @@ func New(options ...Option) loader.Loader {
 		cancelFn: cancel,
 		options:  options,
 		opts:     make(map[string]*Options),
+		cancels:  make(map[string]context.CancelFunc),
 	}
 	return Gorm
 }
@@ -65,30 +79,35 @@ func (c *Component) Init(opts ...loader.Option) error {
 			return errors.New("gorm: config not found")
 		}
 
-		for _, option := range cfg.Options() {
-			for _, opt := range c.opts {
-				option.apply(opt)
-			}
-		}
+		// WithConfig 的配置优先于 New 传入的 Option 应用，热更新时同样生效
+		c.options = append(cfg.Options(), c.options...)
 	}
 
 	// use options
-	for _, option := range c.options {
-		for _, opt := range c.opts {
-			option.apply(opt)
-		}
-	}
+	c.apply(c.opts)
 
 	if len(c.opts) == 0 {
 		return nil
 	}
 
 	for name, opt := range c.opts {
-		db, err := c.connect(name, opt)
+		if opt.Lazy {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(c.ctx)
+		db, err := c.connect(ctx, name, opt)
 		if err != nil {
+			cancel()
+			if opt.Optional {
+				logger.Errorf("gorm: optional instance %s connect error: %v", name, err)
+				c.lazy.Fail(name, err)
+				continue
+			}
 			return err
 		}
 
+		c.cancels[name] = cancel
 		c.clients.Store(name, db)
 	}
 
@@ -98,21 +117,117 @@ func (c *Component) Init(opts ...loader.Option) error {
 	return nil
 }
 
+// Get 获取命名实例，name 为空时返回 default 实例，实例不存在时返回 ErrInstanceNotFound
+func (c *Component) Get(name string) (*gorm.DB, error) {
+	if name == "" {
+		name = defaultName
+	}
+
+	value, ok := c.clients.Load(name)
+	if !ok {
+		return c.lazyConnect(name)
+	}
+
+	return value.(*gorm.DB), nil
+}
+
+// lazyConnect 为延迟连接或启动时连接失败的非关键实例按需建立连接，
+// 通过 singleflight 保证同一实例并发获取时只建立一次连接。按需连接只尝试1次且限制时长，
+// 失败后在冷却期内直接返回上次的错误，避免请求阻塞在启动重试上。
+func (c *Component) lazyConnect(name string) (*gorm.DB, error) {
+	c.mu.Lock()
+	opt, ok := c.opts[name]
+	c.mu.Unlock()
+	if !ok || !(opt.Lazy || opt.Optional) {
+		return nil, fmt.Errorf("gorm: %w, group: %s", ErrInstanceNotFound, name)
+	}
+	if err := c.lazy.Err(name); err != nil {
+		return nil, err
+	}
+
+	value, err, _ := c.group.Do(name, func() (interface{}, error) {
+		if value, ok := c.clients.Load(name); ok {
+			return value, nil
+		}
+
+		cfg := *opt
+		cfg.ConnectMaxAttempts, cfg.ConnectTimeout = 1, connect.OnceTimeout(opt.ConnectTimeout)
+		ctx, cancel := context.WithCancel(c.ctx)
+		db, err := c.connect(ctx, name, &cfg)
+		if err != nil {
+			cancel()
+			c.lazy.Fail(name, err)
+			return nil, err
+		}
+
+		c.lazy.Reset(name)
+		c.swapCancel(name, cancel)
+		c.clients.Store(name, db)
+		return db, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(*gorm.DB), nil
+}
+
+// MustGet 获取命名实例，实例不存在时 panic
+func (c *Component) MustGet(name string) *gorm.DB {
+	client, err := c.Get(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return client
+}
+
+// Instance 获取命名实例，等同于 MustGet，未传 name 时返回 default 实例
 func (c *Component) Instance(name ...string) *gorm.DB {
-	group := defaultName
-	if len(name) > 0 && name[0] != "" {
+	var group string
+	if len(name) > 0 {
 		group = name[0]
 	}
 
-	value, ok := c.clients.Load(group)
-	if !ok {
-		panic("not found instance" + group)
+	return c.MustGet(group)
+}
+
+// Health 检查每个命名实例当前是否可用，尚未建立连接的实例返回包装 ErrNotConnected 的错误，
+// 其中非关键实例与尚未使用的延迟连接实例报告为 DEGRADED
+func (c *Component) Health(ctx context.Context) map[string]error {
+	ret := make(map[string]error)
+	c.clients.Range(func(key, value interface{}) bool {
+		db, err := value.(*gorm.DB).DB()
+		if err == nil {
+			err = db.PingContext(ctx)
+		}
+		ret[key.(string)] = err
+		return true
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, opt := range c.opts {
+		if _, ok := ret[name]; !ok {
+			ret[name] = c.lazy.Health(name, opt.Optional, fmt.Errorf("gorm: %w, group: %s", ErrNotConnected, name))
+		}
 	}
+	return ret
+}
 
-	return value.(*gorm.DB)
+// apply 将组件选项应用到每个命名实例的配置上
+func (c *Component) apply(opts map[string]*Options) {
+	for _, option := range c.options {
+		for _, opt := range opts {
+			option.apply(opt)
+		}
+	}
 }
 
-func (c *Component) connect(name string, cfg *Options) (*gorm.DB, error) {
+// connect 创建命名实例的连接，ctx 控制该实例统计指标协程的生命周期
+func (c *Component) connect(ctx context.Context, name string, opt *Options) (*gorm.DB, error) {
+	// 在副本上补齐默认值，保持 c.opts 为原始配置以便热更新时比较差异
+	cfg := *opt
 	if cfg.MaxIdleConns == 0 {
 		cfg.MaxIdleConns = 16
 	}
@@ -134,51 +249,74 @@ func (c *Component) connect(name string, cfg *Options) (*gorm.DB, error) {
 	if cfg.SlowLogThreshold != 0 {
 		logOpts = append(logOpts, logging.WithSlowThreshold(cfg.SlowLogThreshold))
 	}
-	client, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger:      logging.NewLogging(logOpts...),
-		QueryFields: true,
+	// 关闭自动 ping，由重试按 ctx 控制每次尝试，失败时关闭本次创建的连接池
+	var client *gorm.DB
+	err := retry(name, &cfg, func(ctx context.Context) error {
+		db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+			Logger:               logging.NewLogging(logOpts...),
+			QueryFields:          true,
+			DisableAutomaticPing: true,
+		})
+		if err != nil {
+			return err
+		}
+		if err = ping(ctx, db); err != nil {
+			closeDB(db)
+			return err
+		}
+		client = db
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
+	if err = c.setup(ctx, client, &cfg); err != nil {
+		closeDB(client)
+		return nil, err
+	}
 
+	logger.Infof("%s %s connected success", namespace, name)
+	return client, nil
+}
+
+// setup 注册链路、监控与从库插件并设置连接池参数
+func (c *Component) setup(ctx context.Context, client *gorm.DB, cfg *Options) error {
 	// tracing
 	if !cfg.DisableTrace {
-		err = client.Use(otelgorm.NewPlugin(
+		err := client.Use(otelgorm.NewPlugin(
 			otelgorm.WithAttributes(),
 			otelgorm.WithDBName(cfg.Master.Database),
 		))
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	// metrics
 	if !cfg.DisableMetric {
-		err = client.Use(metrics.New(c.ctx,
+		err := client.Use(metrics.New(ctx,
 			metrics.WithName(cfg.Master.Database),
 			metrics.WithAddr(cfg.Master.Address),
 		))
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	// slaves databases
 	if slaves := c.buildSlaves(cfg.Slaves); slaves != nil {
-		err = client.Use(dbresolver.Register(dbresolver.Config{
+		err := client.Use(dbresolver.Register(dbresolver.Config{
 			Replicas: slaves,
 			Policy:   dbresolver.RandomPolicy{}, // 随机选择
 		}))
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	var DB *sql.DB
-	DB, err = client.DB()
+	DB, err := client.DB()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	DB.SetMaxIdleConns(cfg.MaxIdleConns)
@@ -186,13 +324,29 @@ func (c *Component) connect(name string, cfg *Options) (*gorm.DB, error) {
 	if cfg.ConnMaxLifetime != 0 {
 		DB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 	}
+	return nil
+}
 
-	if err = DB.Ping(); err != nil {
-		return nil, err
+func ping(ctx context.Context, db *gorm.DB) error {
+	s, err := db.DB()
+	if err != nil {
+		return err
 	}
+	return s.PingContext(ctx)
+}
 
-	logger.Infof("%s %s connected success", namespace, name)
-	return client, nil
+// closeDB 关闭连接失败时已创建的连接池，避免重试与热更新时泄漏
+func closeDB(db *gorm.DB) {
+	_ = closePool(db)
+}
+
+// closePool 关闭 db 的底层连接池
+func closePool(db *gorm.DB) error {
+	s, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return s.Close()
 }
 
 func (c *Component) buildSlaves(dns []DSN) []gorm.Dialector {
@@ -224,10 +378,127 @@ func (c *Component) Start(ctx context.Context) error {
 	return nil
 }
 
+// Watch 监听 gorm 配置变化，仅重连发生变化的实例
 func (c *Component) Watch() error {
-	return nil
+	if !c.init {
+		return nil
+	}
+
+	return config.Watch(namespace, c.watch)
 }
 
+func (c *Component) watch(_ string, value kconfig.Value) {
+	opts := make(map[string]*Options)
+	if err := value.Scan(&opts); err != nil {
+		logger.Errorf("gorm: watch scan config error: %v", err)
+		return
+	}
+
+	c.apply(opts)
+	c.reload(opts)
+}
+
+// reload 对比新旧配置，为变更或新增的实例重新建立连接（包括 dbresolver 从库与插件）并原子替换，
+// 旧连接在宽限期后关闭，已开启的事务持有独立连接，不受替换影响。
+func (c *Component) reload(opts map[string]*Options) {
+	// 建立连接可能按 connect_timeout 重试，期间只持有 reloadMu，c.mu 仅在替换时短暂持有
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	for name, opt := range opts {
+		old, ok := c.opts[name]
+		if ok && equal(old, opt) {
+			continue
+		}
+		c.lazy.Reset(name)
+
+		// 延迟连接的实例只移除旧连接，下次获取时按新配置建立连接
+		if opt.Lazy {
+			if prev, loaded := c.clients.LoadAndDelete(name); loaded {
+				c.drain(name, prev.(*gorm.DB), c.swapCancel(name, nil), opt.GracePeriod)
+			}
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(c.ctx)
+		db, err := c.connect(ctx, name, opt)
+		if err != nil {
+			cancel()
+			logger.Errorf("gorm: reload %s error: %v", name, err)
+			if ok {
+				opts[name] = old
+			} else {
+				delete(opts, name)
+			}
+			continue
+		}
+
+		prevCancel := c.swapCancel(name, cancel)
+		if prev, loaded := c.clients.Swap(name, db); loaded {
+			c.drain(name, prev.(*gorm.DB), prevCancel, opt.GracePeriod)
+		}
+		logger.Infof("%s %s reload success", namespace, name)
+	}
+
+	for name, old := range c.opts {
+		if _, ok := opts[name]; ok {
+			continue
+		}
+
+		if prev, loaded := c.clients.LoadAndDelete(name); loaded {
+			c.drain(name, prev.(*gorm.DB), c.swapCancel(name, nil), old.GracePeriod)
+		}
+		c.lazy.Reset(name)
+		logger.Infof("%s %s removed", namespace, name)
+	}
+
+	c.mu.Lock()
+	c.opts = opts
+	c.mu.Unlock()
+}
+
+// swapCancel 替换实例统计协程的取消函数并返回旧的取消函数，cancel 为 nil 时删除
+func (c *Component) swapCancel(name string, cancel context.CancelFunc) context.CancelFunc {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev := c.cancels[name]
+	if cancel == nil {
+		delete(c.cancels, name)
+	} else {
+		c.cancels[name] = cancel
+	}
+	return prev
+}
+
+// equal 比较两份配置是否一致，新增函数或接口类型的字段时需在此排除
+func equal(a, b *Options) bool {
+	return reflect.DeepEqual(*a, *b)
+}
+
+// drain 在宽限期后关闭旧连接池并停止其统计指标协程
+func (c *Component) drain(name string, db *gorm.DB, cancel context.CancelFunc, grace time.Duration) {
+	if grace == 0 {
+		grace = defaultGracePeriod
+	}
+
+	timer := time.AfterFunc(grace, func() {
+		c.drains.Delete(db)
+		if cancel != nil {
+			cancel()
+		}
+
+		if err := closePool(db); err != nil {
+			logger.Errorf("gorm: close %s old db error: %v", name, err)
+			return
+		}
+		logger.Infof("%s %s old db closed", namespace, name)
+	})
+	c.drains.Store(db, timer)
+}
+
+// Stop 停止统计协程并关闭全部连接池（含热更新后尚在宽限期内的旧连接池），
+// ctx 结束时不再等待并返回超时错误，关闭失败的错误会被合并返回
 func (c *Component) Stop(ctx context.Context) error {
 	if !c.init {
 		return nil
@@ -236,23 +507,51 @@ func (c *Component) Stop(ctx context.Context) error {
 		c.cancelFn()
 	}
 
+	var dbs []*gorm.DB
+	names := make(map[*gorm.DB]string)
 	c.clients.Range(func(key, value interface{}) bool {
-
 		db := value.(*gorm.DB)
-		s, err := db.DB()
-		if err != nil {
-			return true
-		}
-
-		err = s.Close()
-		if err != nil {
-			return true
+		dbs = append(dbs, db)
+		names[db] = key.(string)
+		c.clients.Delete(key)
+		return true
+	})
+	c.drains.Range(func(key, value interface{}) bool {
+		// 定时器已触发的旧连接池由定时器负责关闭
+		if value.(*time.Timer).Stop() {
+			dbs = append(dbs, key.(*gorm.DB))
 		}
-
+		c.drains.Delete(key)
 		return true
 	})
 
-	c.clients = sync.Map{}
+	errc := make(chan error, len(dbs))
+	for _, db := range dbs {
+		go func(db *gorm.DB) {
+			if err := closePool(db); err != nil {
+				name, ok := names[db]
+				if !ok {
+					name = "old"
+				}
+				errc <- fmt.Errorf("gorm: close %s %w", name, err)
+				return
+			}
+			errc <- nil
+		}(db)
+	}
+
+	var errs []error
+	for range dbs {
+		select {
+		case err := <-errc:
+			errs = append(errs, err)
+		case <-ctx.Done():
+			return errors.Join(append(errs, fmt.Errorf("gorm: stop %w", ctx.Err()))...)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
 
 	logger.Infof("Component [%s] stop success", c.String())
 	return nil
@@ -0,0 +1,82 @@
+package gorm_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	kconfig "github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+	"github.com/nextmicro/next-component/gorm"
+	"github.com/nextmicro/next/config"
+)
+
+func loadConfig(t *testing.T, content string) {
+	filename := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := kconfig.New(kconfig.WithSource(file.NewSource(filename)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	config.DefaultConfig = c
+	t.Cleanup(func() { _ = c.Close() })
+}
+
+// closedAddr 返回一个没有监听的本地地址
+func closedAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+	return addr
+}
+
+func TestComponent_ConnectRetry(t *testing.T) {
+	loadConfig(t, `{"gorm":{"default":{"master":{"address":"`+closedAddr(t)+`"},"connect_max_attempts":2,"connect_min_backoff":1000000}}}`)
+
+	err := gorm.New(gorm.WithDisableTrace(), gorm.WithDisableMetric()).Init()
+	if err == nil || !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Fatalf("Init() error = %v, want failed after 2 attempts", err)
+	}
+}
+
+func TestComponent_LazyAndOptional(t *testing.T) {
+	addr := closedAddr(t)
+	loadConfig(t, `{"gorm":{"default":{"master":{"address":"`+addr+`"},"lazy":true},"backup":{"master":{"address":"`+addr+`"},"optional":true}}}`)
+
+	c := gorm.New(gorm.WithDisableTrace(), gorm.WithDisableMetric()).(*gorm.Component)
+	if err := c.Init(); err != nil {
+		t.Fatalf("Init() error = %v, want lazy and optional instances skipped", err)
+	}
+
+	for _, name := range []string{"", "backup"} {
+		if _, err := c.Get(name); err == nil {
+			t.Fatalf("Get(%q) error = nil, want connect error", name)
+		}
+	}
+	if _, err := c.Get("missing"); !errors.Is(err, gorm.ErrInstanceNotFound) {
+		t.Fatalf("Get(missing) error = %v, want %v", err, gorm.ErrInstanceNotFound)
+	}
+
+	// 连接失败的 lazy 实例为 DOWN，optional 实例为 DEGRADED
+	health := c.Health(context.Background())
+	for name, degraded := range map[string]bool{"default": false, "backup": true} {
+		err := health[name]
+		if !errors.Is(err, gorm.ErrNotConnected) {
+			t.Fatalf("health %s = %v, want %v", name, err, gorm.ErrNotConnected)
+		}
+		var d interface{ Degraded() bool }
+		if got := errors.As(err, &d) && d.Degraded(); got != degraded {
+			t.Fatalf("health %s degraded = %v, want %v", name, got, degraded)
+		}
+	}
+}
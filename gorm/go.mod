@@ -8,8 +8,10 @@ require (
 	github.com/nextmicro/gokit/timex v1.0.0
 	github.com/nextmicro/logger v1.0.3
 	github.com/nextmicro/next v1.0.6
+	github.com/nextmicro/next-component/internal v0.0.0-00010101000000-000000000000
 	github.com/uptrace/opentelemetry-go-extra/otelgorm v0.2.3
 	go.opentelemetry.io/otel v1.21.0
+	golang.org/x/sync v0.5.0
 	gorm.io/driver/mysql v1.5.1
 	gorm.io/gorm v1.25.5
 	gorm.io/plugin/dbresolver v1.4.7
@@ -63,7 +65,6 @@ require (
 	go.uber.org/zap v1.26.0 // indirect
 	golang.org/x/crypto v0.15.0 // indirect
 	golang.org/x/net v0.18.0 // indirect
-	golang.org/x/sync v0.5.0 // indirect
 	golang.org/x/sys v0.14.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 	golang.org/x/time v0.4.0 // indirect
@@ -74,3 +75,5 @@ require (
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/nextmicro/next-component/internal => ../internal
@@ -62,9 +62,17 @@ type Options struct {
 	MaxOpenConns     int           `json:"max_open_conns"`     // 最大活动连接数，默认100
 	ConnMaxLifetime  time.Duration `json:"conn_max_lifetime"`  // 连接的最大存活时间，默认300s
 	SlowLogThreshold time.Duration `json:"slow_log_threshold"` // 慢日志阈值，默认500ms
+	GracePeriod      time.Duration `json:"grace_period"`       // 热更新后旧连接关闭前的等待时间，默认30s
+	Lazy             bool          `json:"lazy"`               // 延迟连接，首次获取实例时才建立连接
+	Optional         bool          `json:"optional"`           // 非关键实例，启动时连接失败仅记录日志，不影响组件初始化
 	DisableMetric    bool          `json:"disable_metric"`     // 是否禁用监控，默认开启
 	DisableTrace     bool          `json:"disable_trace"`      // 是否禁用链路追踪，默认开启
 	DisableLogging   bool          `json:"disable_logging"`    // 是否禁用，记录请求数据
+
+	ConnectMaxAttempts int           `json:"connect_max_attempts"` // 建立连接的最大尝试次数，默认1次即不重试
+	ConnectMinBackoff  time.Duration `json:"connect_min_backoff"`  // 建立连接重试的初始间隔，默认1s，每次翻倍
+	ConnectMaxBackoff  time.Duration `json:"connect_max_backoff"`  // 建立连接重试的最大间隔，默认10s
+	ConnectTimeout     time.Duration `json:"connect_timeout"`      // 建立连接（含重试）的总时长，默认不限制
 }
 
 type DSN struct {
@@ -77,8 +85,9 @@ type DSN struct {
 }
 
 const (
-	namespace   = "gorm"
-	defaultName = "default"
+	namespace          = "gorm"
+	defaultName        = "default"
+	defaultGracePeriod = 30 * time.Second
 )
 
 // WithConfig sets the gorm config
@@ -112,6 +121,21 @@ func (o *Options) Options() []Option {
 	if o.SlowLogThreshold != 0 {
 		opts = append(opts, WithSlowLogThreshold(o.SlowLogThreshold))
 	}
+	if o.GracePeriod != 0 {
+		opts = append(opts, WithGracePeriod(o.GracePeriod))
+	}
+	if o.Lazy {
+		opts = append(opts, WithLazy())
+	}
+	if o.Optional {
+		opts = append(opts, WithOptional())
+	}
+	if o.ConnectMaxAttempts != 0 || o.ConnectMinBackoff != 0 || o.ConnectMaxBackoff != 0 {
+		opts = append(opts, WithConnectRetry(o.ConnectMaxAttempts, o.ConnectMinBackoff, o.ConnectMaxBackoff))
+	}
+	if o.ConnectTimeout != 0 {
+		opts = append(opts, WithConnectTimeout(o.ConnectTimeout))
+	}
 	if o.DisableMetric {
 		opts = append(opts, WithDisableMetric())
 	}
@@ -166,6 +190,43 @@ func WithSlowLogThreshold(v time.Duration) Option {
 	})
 }
 
+// WithGracePeriod sets the wait time before closing the old connections after hot reload.
+func WithGracePeriod(v time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.GracePeriod = v
+	})
+}
+
+// WithLazy defers connecting until the instance is first requested.
+func WithLazy() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Lazy = true
+	})
+}
+
+// WithOptional marks the instance as non-critical, a connect failure on init is only logged.
+func WithOptional() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Optional = true
+	})
+}
+
+// WithConnectRetry sets the max connect attempts and the backoff between them.
+func WithConnectRetry(maxAttempts int, minBackoff, maxBackoff time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.ConnectMaxAttempts = maxAttempts
+		cfg.ConnectMinBackoff = minBackoff
+		cfg.ConnectMaxBackoff = maxBackoff
+	})
+}
+
+// WithConnectTimeout sets the overall deadline for connecting, retries included.
+func WithConnectTimeout(v time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.ConnectTimeout = v
+	})
+}
+
 // WithDisableMetric disables the metric for the database.
 func WithDisableMetric() Option {
 	return OptionFunc(func(cfg *Options) {
@@ -0,0 +1,42 @@
+package gorm
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOptions_Options(t *testing.T) {
+	tests := []Options{
+		{
+			Master:             DSN{Address: "127.0.0.1:3306", Database: "test"},
+			Slaves:             []DSN{{Address: "127.0.0.1:3307", Database: "test"}},
+			MaxIdleConns:       8,
+			MaxOpenConns:       64,
+			ConnMaxLifetime:    time.Minute,
+			SlowLogThreshold:   time.Second,
+			GracePeriod:        time.Second,
+			Lazy:               true,
+			Optional:           true,
+			ConnectMaxAttempts: 3,
+			ConnectMinBackoff:  time.Millisecond,
+			ConnectMaxBackoff:  time.Second,
+			ConnectTimeout:     time.Minute,
+			DisableMetric:      true,
+			DisableTrace:       true,
+			DisableLogging:     true,
+		},
+		// 只设置退避间隔时同样生效
+		{ConnectMinBackoff: time.Millisecond},
+		{ConnectMaxBackoff: time.Second},
+	}
+	for _, want := range tests {
+		var got Options
+		for _, opt := range want.Options() {
+			opt.apply(&got)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Options() applied = %+v, want %+v", got, want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package gorm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// newPool 创建不建立连接的连接池
+func newPool(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "root:root@tcp(127.0.0.1:1)/test",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { closeDB(db) })
+	return db
+}
+
+func closed(t *testing.T, db *gorm.DB) bool {
+	t.Helper()
+
+	s, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Ping()
+	return err != nil && strings.Contains(err.Error(), "database is closed")
+}
+
+func drains(c *Component) int {
+	var n int
+	c.drains.Range(func(_, _ interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+func newReloadComponent(t *testing.T, grace time.Duration) (*Component, *gorm.DB, *gorm.DB) {
+	t.Helper()
+
+	c := New().(*Component)
+	c.init = true
+	c.opts = map[string]*Options{
+		defaultName: {Master: DSN{Address: "127.0.0.1:3306"}, GracePeriod: grace},
+		"removed":   {Master: DSN{Address: "127.0.0.1:3307"}, GracePeriod: grace},
+	}
+	db, removed := newPool(t), newPool(t)
+	c.clients.Store(defaultName, db)
+	c.clients.Store("removed", removed)
+	return c, db, removed
+}
+
+func TestComponent_ReloadDrain(t *testing.T) {
+	c, db, removed := newReloadComponent(t, 20*time.Millisecond)
+
+	// 配置未变化时不替换
+	c.reload(map[string]*Options{
+		defaultName: {Master: DSN{Address: "127.0.0.1:3306"}, GracePeriod: 20 * time.Millisecond},
+		"removed":   {Master: DSN{Address: "127.0.0.1:3307"}, GracePeriod: 20 * time.Millisecond},
+	})
+	if got, _ := c.clients.Load(defaultName); got != db || drains(c) != 0 {
+		t.Fatalf("unchanged reload replaced client, drains = %d", drains(c))
+	}
+
+	// 变更为延迟连接的实例与被删除的实例在宽限期后关闭
+	c.reload(map[string]*Options{
+		defaultName: {Master: DSN{Address: "127.0.0.1:3308"}, Lazy: true, GracePeriod: 20 * time.Millisecond},
+	})
+	if _, ok := c.clients.Load(defaultName); ok {
+		t.Fatal("changed lazy instance still holds the old client")
+	}
+	if _, ok := c.clients.Load("removed"); ok {
+		t.Fatal("removed instance still holds the old client")
+	}
+	if n := drains(c); n != 2 || closed(t, db) || closed(t, removed) {
+		t.Fatalf("drains = %d, want 2 pending drains before the grace period", n)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for drains(c) > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !closed(t, db) || !closed(t, removed) {
+		t.Fatal("old pools not closed after the grace period")
+	}
+}
+
+func TestComponent_StopClosesDrains(t *testing.T) {
+	c, db, removed := newReloadComponent(t, time.Hour)
+
+	c.reload(map[string]*Options{
+		defaultName: {Master: DSN{Address: "127.0.0.1:3306"}, GracePeriod: time.Hour},
+	})
+	if n := drains(c); n != 1 {
+		t.Fatalf("drains = %d, want 1", n)
+	}
+
+	if err := c.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if !closed(t, db) || !closed(t, removed) {
+		t.Fatal("Stop() did not close active and draining pools")
+	}
+	if n := drains(c); n != 0 {
+		t.Fatalf("drains = %d after Stop, want 0", n)
+	}
+}
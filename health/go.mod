@@ -0,0 +1,3 @@
+module github.com/nextmicro/next-component/health
+
+go 1.21.0
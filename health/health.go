@@ -0,0 +1,168 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp       = "UP"
+	StatusDown     = "DOWN"
+	StatusDegraded = "DEGRADED"
+)
+
+// degrader 由非关键的错误实现，如未连接的 optional 实例，这类实例报告为 DEGRADED，不影响整体可用
+type degrader interface {
+	Degraded() bool
+}
+
+// Checker 组件健康检查接口，返回每个命名实例的检查结果，nil 表示健康
+type Checker interface {
+	Health(ctx context.Context) map[string]error
+}
+
+// CheckerFunc is an adapter to allow the use of ordinary functions as Checker.
+type CheckerFunc func(ctx context.Context) map[string]error
+
+// Health calls fn(ctx).
+func (fn CheckerFunc) Health(ctx context.Context) map[string]error {
+	return fn(ctx)
+}
+
+// Option is health option.
+type Option func(o *options)
+
+type options struct {
+	timeout time.Duration
+}
+
+// WithTimeout 设置单次检查的超时时间，默认3s
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// Detail 单个实例的检查结果
+type Detail struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Result 聚合后的检查结果，任一实例不健康则整体为 DOWN，仅存在 DEGRADED 实例时整体为 DEGRADED
+type Result struct {
+	Status     string                       `json:"status"`
+	Components map[string]map[string]Detail `json:"components,omitempty"`
+}
+
+// Health 聚合多个组件的健康检查结果
+type Health struct {
+	opt      options
+	mu       sync.RWMutex
+	checkers map[string]Checker
+}
+
+// New 创建健康检查聚合器
+func New(opts ...Option) *Health {
+	opt := options{
+		timeout: 3 * time.Second,
+	}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	return &Health{
+		opt:      opt,
+		checkers: make(map[string]Checker),
+	}
+}
+
+// Register 注册组件，name 一般为组件的 String()
+func (h *Health) Register(name string, checker Checker) *Health {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checkers[name] = checker
+	return h
+}
+
+// Check 并发检查所有已注册的组件
+func (h *Health) Check(ctx context.Context) Result {
+	if h.opt.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.opt.timeout)
+		defer cancel()
+	}
+
+	h.mu.RLock()
+	checkers := make(map[string]Checker, len(h.checkers))
+	for name, checker := range h.checkers {
+		checkers[name] = checker
+	}
+	h.mu.RUnlock()
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = Result{
+			Status:     StatusUp,
+			Components: make(map[string]map[string]Detail, len(checkers)),
+		}
+	)
+	for name, checker := range checkers {
+		wg.Add(1)
+		go func(name string, checker Checker) {
+			defer wg.Done()
+
+			errs := checker.Health(ctx)
+			details := make(map[string]Detail, len(errs))
+			up, degraded := true, false
+			for instance, err := range errs {
+				var d degrader
+				switch {
+				case err == nil:
+					details[instance] = Detail{Status: StatusUp}
+				case errors.As(err, &d) && d.Degraded():
+					degraded = true
+					details[instance] = Detail{Status: StatusDegraded, Error: err.Error()}
+				default:
+					up = false
+					details[instance] = Detail{Status: StatusDown, Error: err.Error()}
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Components[name] = details
+			if !up {
+				result.Status = StatusDown
+			} else if degraded && result.Status == StatusUp {
+				result.Status = StatusDegraded
+			}
+		}(name, checker)
+	}
+	wg.Wait()
+
+	return result
+}
+
+// Handler 返回可挂载为 readiness/liveness 探针的 http.Handler，
+// 整体为 UP 或 DEGRADED 时返回 200，DOWN 时返回 503，响应体为 JSON 格式的 Result。
+func (h *Health) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result := h.Check(r.Context())
+
+		code := http.StatusOK
+		if result.Status == StatusDown {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(result)
+	})
+}
@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealth_Handler(t *testing.T) {
+	up := CheckerFunc(func(ctx context.Context) map[string]error {
+		return map[string]error{"default": nil}
+	})
+	down := CheckerFunc(func(ctx context.Context) map[string]error {
+		return map[string]error{"default": nil, "slave": errors.New("connection refused")}
+	})
+	degraded := CheckerFunc(func(ctx context.Context) map[string]error {
+		return map[string]error{"default": nil, "backup": degradedError{}}
+	})
+
+	tests := []struct {
+		name     string
+		checkers map[string]Checker
+		code     int
+		status   string
+	}{
+		{"empty", nil, http.StatusOK, StatusUp},
+		{"up", map[string]Checker{"go-redis": up}, http.StatusOK, StatusUp},
+		{"down", map[string]Checker{"go-redis": up, "gorm": down}, http.StatusServiceUnavailable, StatusDown},
+		{"degraded", map[string]Checker{"go-redis": up, "gorm": degraded}, http.StatusOK, StatusDegraded},
+		{"down and degraded", map[string]Checker{"go-redis": degraded, "gorm": down}, http.StatusServiceUnavailable, StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New()
+			for name, checker := range tt.checkers {
+				h.Register(name, checker)
+			}
+
+			rec := httptest.NewRecorder()
+			h.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			if rec.Code != tt.code {
+				t.Fatalf("code = %d, want %d", rec.Code, tt.code)
+			}
+
+			var result Result
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.status {
+				t.Fatalf("status = %s, want %s", result.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestHealth_CheckDetail(t *testing.T) {
+	h := New().Register("gorm", CheckerFunc(func(ctx context.Context) map[string]error {
+		return map[string]error{"slave": errors.New("timeout")}
+	}))
+
+	result := h.Check(context.Background())
+	detail := result.Components["gorm"]["slave"]
+	if detail.Status != StatusDown || detail.Error != "timeout" {
+		t.Fatalf("unexpected detail: %+v", detail)
+	}
+}
+
+type degradedError struct{}
+
+func (degradedError) Error() string  { return "not connected" }
+func (degradedError) Degraded() bool { return true }
+
+func TestHealth_CheckDegraded(t *testing.T) {
+	h := New().Register("mongo", CheckerFunc(func(ctx context.Context) map[string]error {
+		return map[string]error{"backup": fmt.Errorf("mongo: %w", degradedError{})}
+	}))
+
+	result := h.Check(context.Background())
+	detail := result.Components["mongo"]["backup"]
+	if result.Status != StatusDegraded || detail.Status != StatusDegraded || detail.Error != "mongo: not connected" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
@@ -0,0 +1,93 @@
+package connect
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLazyTimeout 按需连接单次尝试的最长时间
+	DefaultLazyTimeout = 3 * time.Second
+	// DefaultLazyCooldown 按需连接失败后的冷却时间，期间不再尝试连接，直接返回上次的错误
+	DefaultLazyCooldown = 5 * time.Second
+)
+
+// OnceTimeout 返回按需连接单次尝试的超时时间，未配置或超过 DefaultLazyTimeout 时为 DefaultLazyTimeout
+func OnceTimeout(timeout time.Duration) time.Duration {
+	if timeout <= 0 || timeout > DefaultLazyTimeout {
+		return DefaultLazyTimeout
+	}
+	return timeout
+}
+
+// Lazy 记录延迟连接与非关键实例最近一次连接失败的原因，零值可直接使用
+type Lazy struct {
+	mu       sync.Mutex
+	failures map[string]failure
+}
+
+type failure struct {
+	at  time.Time
+	err error
+}
+
+// Err 返回冷却期内上次连接失败的错误，不在冷却期时返回 nil
+func (l *Lazy) Err(name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, ok := l.failures[name]
+	if !ok || time.Since(f.at) >= DefaultLazyCooldown {
+		return nil
+	}
+	return f.err
+}
+
+// Fail 记录连接失败
+func (l *Lazy) Fail(name string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.failures == nil {
+		l.failures = make(map[string]failure)
+	}
+	l.failures[name] = failure{at: time.Now(), err: err}
+}
+
+// Reset 清除失败记录，连接成功或配置变更时调用
+func (l *Lazy) Reset(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.failures, name)
+}
+
+// Health 返回未连接实例的健康检查结果，err 为组件的未连接错误。
+// 非关键实例与尚未尝试连接的延迟连接实例报告为 DEGRADED，延迟连接失败的实例报告为 DOWN
+func (l *Lazy) Health(name string, optional bool, err error) error {
+	l.mu.Lock()
+	f, failed := l.failures[name]
+	l.mu.Unlock()
+
+	if failed {
+		err = fmt.Errorf("%w: %w", err, f.err)
+	}
+	if optional || !failed {
+		return Degraded(err)
+	}
+	return err
+}
+
+// Degraded 包装非关键的错误，health 包将其报告为 DEGRADED，不影响整体可用
+func Degraded(err error) error {
+	return &degraded{err: err}
+}
+
+type degraded struct {
+	err error
+}
+
+func (e *degraded) Error() string  { return e.err.Error() }
+func (e *degraded) Unwrap() error  { return e.err }
+func (e *degraded) Degraded() bool { return true }
@@ -0,0 +1,87 @@
+package connect
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOnceTimeout(t *testing.T) {
+	tests := map[time.Duration]time.Duration{
+		0:                  DefaultLazyTimeout,
+		-time.Second:       DefaultLazyTimeout,
+		time.Second:        time.Second,
+		time.Minute:        DefaultLazyTimeout,
+		DefaultLazyTimeout: DefaultLazyTimeout,
+	}
+	for timeout, want := range tests {
+		if got := OnceTimeout(timeout); got != want {
+			t.Errorf("OnceTimeout(%v) = %v, want %v", timeout, got, want)
+		}
+	}
+}
+
+func TestLazy(t *testing.T) {
+	var l Lazy
+	errConnect := errors.New("connection refused")
+
+	if err := l.Err("default"); err != nil {
+		t.Fatalf("Err() = %v, want nil before any failure", err)
+	}
+
+	l.Fail("default", errConnect)
+	if err := l.Err("default"); !errors.Is(err, errConnect) {
+		t.Fatalf("Err() = %v, want %v within cooldown", err, errConnect)
+	}
+	if err := l.Err("other"); err != nil {
+		t.Fatalf("Err(other) = %v, want nil", err)
+	}
+
+	// 冷却期过后允许再次尝试
+	l.failures["default"] = failure{at: time.Now().Add(-DefaultLazyCooldown), err: errConnect}
+	if err := l.Err("default"); err != nil {
+		t.Fatalf("Err() = %v, want nil after cooldown", err)
+	}
+
+	l.Fail("default", errConnect)
+	l.Reset("default")
+	if err := l.Err("default"); err != nil {
+		t.Fatalf("Err() = %v, want nil after Reset", err)
+	}
+}
+
+func TestLazy_Health(t *testing.T) {
+	errNotConnected := errors.New("not connected")
+	errConnect := errors.New("connection refused")
+
+	var l Lazy
+	l.Fail("failed", errConnect)
+
+	tests := []struct {
+		name      string
+		instance  string
+		optional  bool
+		degraded  bool
+		wantCause bool
+	}{
+		{"lazy never connected", "idle", false, true, false},
+		{"optional never connected", "idle", true, true, false},
+		{"lazy failed", "failed", false, false, true},
+		{"optional failed", "failed", true, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := l.Health(tt.instance, tt.optional, errNotConnected)
+			if !errors.Is(err, errNotConnected) {
+				t.Fatalf("Health() = %v, want wrapping %v", err, errNotConnected)
+			}
+			if got := errors.Is(err, errConnect); got != tt.wantCause {
+				t.Fatalf("Health() = %v, wraps cause = %v, want %v", err, got, tt.wantCause)
+			}
+			var d interface{ Degraded() bool }
+			if got := errors.As(err, &d) && d.Degraded(); got != tt.degraded {
+				t.Fatalf("Health() degraded = %v, want %v", got, tt.degraded)
+			}
+		})
+	}
+}
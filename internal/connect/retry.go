@@ -0,0 +1,78 @@
+// Package connect 组件启动连接的重试，各组件在此基础上记录各自的监控与日志
+package connect
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = 10 * time.Second
+)
+
+// Retry 启动连接的重试配置，独立于客户端自身针对单个请求的重试
+type Retry struct {
+	MaxAttempts int           // 最大尝试次数，小于等于0时只尝试1次
+	MinBackoff  time.Duration // 首次重试前的等待时间，默认1s，之后每次翻倍
+	MaxBackoff  time.Duration // 最大等待时间，默认10s
+	Timeout     time.Duration // 全部尝试的总时长，0 为不限制
+
+	// OnAttempt 每次尝试后调用，err 为本次结果，backoff 为下次重试前的等待时间，不再重试时为0
+	OnAttempt func(attempt int, err error, backoff time.Duration)
+}
+
+// Do 执行 ping，失败时按指数退避重试，直到成功、达到最大尝试次数或超过总时长
+func (r Retry) Do(ping func(ctx context.Context) error) error {
+	attempts := r.MaxAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	backoff := r.MinBackoff
+	if backoff <= 0 {
+		backoff = DefaultMinBackoff
+	}
+	maxBackoff := r.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	ctx := context.Background()
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := ping(ctx)
+		if err == nil || attempt >= attempts {
+			r.notify(attempt, err, 0)
+			if err != nil {
+				return fmt.Errorf("failed after %d attempts: %w", attempt, err)
+			}
+			return nil
+		}
+
+		r.notify(attempt, err, backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("deadline exceeded after %d attempts: %w", attempt, err)
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func (r Retry) notify(attempt int, err error, backoff time.Duration) {
+	if r.OnAttempt != nil {
+		r.OnAttempt(attempt, err, backoff)
+	}
+}
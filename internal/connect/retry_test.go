@@ -0,0 +1,103 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetry_Do(t *testing.T) {
+	errPing := errors.New("ping")
+
+	type call struct {
+		attempt int
+		err     error
+		backoff time.Duration
+	}
+	tests := []struct {
+		name    string
+		retry   Retry
+		fails   int
+		wantErr string
+		want    []call
+	}{
+		{
+			name: "success",
+			want: []call{{1, nil, 0}},
+		},
+		{
+			name:    "single attempt by default",
+			fails:   1,
+			wantErr: "failed after 1 attempts: ping",
+			want:    []call{{1, errPing, 0}},
+		},
+		{
+			name:  "retry until success",
+			retry: Retry{MaxAttempts: 5, MinBackoff: time.Millisecond, MaxBackoff: 3 * time.Millisecond},
+			fails: 3,
+			want: []call{
+				{1, errPing, time.Millisecond},
+				{2, errPing, 2 * time.Millisecond},
+				{3, errPing, 3 * time.Millisecond},
+				{4, nil, 0},
+			},
+		},
+		{
+			name:    "max attempts",
+			retry:   Retry{MaxAttempts: 2, MinBackoff: time.Millisecond},
+			fails:   5,
+			wantErr: "failed after 2 attempts: ping",
+			want:    []call{{1, errPing, time.Millisecond}, {2, errPing, 0}},
+		},
+		{
+			name:    "timeout",
+			retry:   Retry{MaxAttempts: 5, MinBackoff: time.Second, Timeout: 10 * time.Millisecond},
+			fails:   5,
+			wantErr: "deadline exceeded after 1 attempts: ping",
+			want:    []call{{1, errPing, time.Second}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []call
+			tt.retry.OnAttempt = func(attempt int, err error, backoff time.Duration) {
+				calls = append(calls, call{attempt, err, backoff})
+			}
+
+			var n int
+			err := tt.retry.Do(func(ctx context.Context) error {
+				if n++; n <= tt.fails {
+					return errPing
+				}
+				return nil
+			})
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Do() error = %v, want %q", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errPing) {
+				t.Fatalf("Do() error = %v, want wrapping %v", err, errPing)
+			}
+			if len(calls) != len(tt.want) {
+				t.Fatalf("OnAttempt calls = %v, want %v", calls, tt.want)
+			}
+			for i := range tt.want {
+				if calls[i] != tt.want[i] {
+					t.Fatalf("OnAttempt calls = %v, want %v", calls, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRetry_DoTimeoutContext(t *testing.T) {
+	r := Retry{Timeout: 10 * time.Millisecond}
+	err := r.Do(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Do() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,58 @@
+// Package envelope 为没有 header 的消息（NSQ、redis pub/sub）携带链路信息等元数据。
+//
+// 元数据以信封形式写在消息体前：
+//
+//	magic(4) | headers 长度(uint32, 大端) | headers(JSON) | body
+//
+// 未携带 magic 的消息视为普通消息，解包时原样返回，
+// 因此写入与不写入信封的生产者可以向同一主题或频道投递。
+package envelope
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+)
+
+var magic = []byte{0x00, 'N', 'X', 'T'}
+
+const headerLenSize = 4
+
+// Encode 将 headers 与消息体打包，headers 为空时直接返回消息体
+func Encode(headers map[string]string, body []byte) ([]byte, error) {
+	if len(headers) == 0 {
+		return body, nil
+	}
+
+	h, err := json.Marshal(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0, len(magic)+headerLenSize+len(h)+len(body))
+	buf = append(buf, magic...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(h)))
+	buf = append(buf, h...)
+	buf = append(buf, body...)
+	return buf, nil
+}
+
+// Decode 解包消息，未携带信封或信封不完整的消息返回 nil headers 与原始消息体
+func Decode(data []byte) (map[string]string, []byte) {
+	if !bytes.HasPrefix(data, magic) || len(data) < len(magic)+headerLenSize {
+		return nil, data
+	}
+
+	rest := data[len(magic):]
+	n := binary.BigEndian.Uint32(rest)
+	rest = rest[headerLenSize:]
+	if uint64(len(rest)) < uint64(n) {
+		return nil, data
+	}
+
+	headers := make(map[string]string)
+	if err := json.Unmarshal(rest[:n], &headers); err != nil {
+		return nil, data
+	}
+	return headers, rest[n:]
+}
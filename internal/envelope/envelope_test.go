@@ -0,0 +1,59 @@
+package envelope
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		body    []byte
+	}{
+		{"trace", map[string]string{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}, []byte("hello")},
+		{"multiple headers", map[string]string{"a": "1", "b": "2"}, []byte(`{"id":1}`)},
+		{"empty body", map[string]string{"a": "1"}, []byte{}},
+		{"binary body", map[string]string{"a": "1"}, append(append([]byte{}, magic...), 0xff, 0x00)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Encode(tt.headers, tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			h, body := Decode(data)
+			if !reflect.DeepEqual(h, tt.headers) || !bytes.Equal(body, tt.body) {
+				t.Fatalf("Decode() = %v, %q, want %v, %q", h, body, tt.headers, tt.body)
+			}
+		})
+	}
+}
+
+func TestEncode_NoHeaders(t *testing.T) {
+	for _, headers := range []map[string]string{nil, {}} {
+		data, err := Encode(headers, []byte("hello"))
+		if err != nil || string(data) != "hello" {
+			t.Fatalf("Encode(%v) = %q, %v, want the raw body", headers, data, err)
+		}
+	}
+}
+
+func TestDecode_Raw(t *testing.T) {
+	for _, raw := range [][]byte{
+		[]byte("hello"),
+		[]byte(`{"traceparent":"x"}`),
+		nil,
+		magic,
+		append(append([]byte{}, magic...), 0, 0),
+		append(append([]byte{}, magic...), 0, 0, 0, 9, '{'),
+		append(append([]byte{}, magic...), 0, 0, 0, 3, 'b', 'a', 'd', 'x'),
+	} {
+		h, body := Decode(raw)
+		if h != nil || !bytes.Equal(body, raw) {
+			t.Fatalf("Decode(%q) = %v, %q, want the raw payload", raw, h, body)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/nextmicro/next-component/internal
+
+go 1.21.0
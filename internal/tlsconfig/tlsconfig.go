@@ -0,0 +1,49 @@
+// Package tlsconfig 组件共用的 TLS 配置
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Config TLS 证书配置
+type Config struct {
+	CAFile             string `json:"ca_file"`              // CA 证书
+	CertFile           string `json:"cert_file"`            // 客户端证书
+	KeyFile            string `json:"key_file"`             // 客户端私钥
+	ServerName         string `json:"server_name"`          // 校验的服务端域名
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"` // 是否跳过服务端证书校验
+}
+
+// Load 根据配置加载 CA 与客户端证书
+func Load(c *Config) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		ca, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("no certificates found in ca file")
+		}
+		cfg.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,91 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert 生成自签名证书与私钥，返回文件路径
+func writeCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestLoad(t *testing.T) {
+	certFile, keyFile := writeCert(t)
+
+	cfg, err := Load(&Config{
+		CAFile:             certFile,
+		CertFile:           certFile,
+		KeyFile:            keyFile,
+		ServerName:         "example.com",
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.RootCAs == nil || len(cfg.Certificates) != 1 {
+		t.Fatalf("Load() RootCAs = %v, Certificates = %d, want both loaded", cfg.RootCAs, len(cfg.Certificates))
+	}
+	if cfg.ServerName != "example.com" || !cfg.InsecureSkipVerify {
+		t.Fatalf("Load() ServerName = %q, InsecureSkipVerify = %v", cfg.ServerName, cfg.InsecureSkipVerify)
+	}
+}
+
+func TestLoad_Error(t *testing.T) {
+	certFile, _ := writeCert(t)
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a pem"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, c := range map[string]*Config{
+		"missing ca":      {CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"invalid ca":      {CAFile: empty},
+		"missing key":     {CertFile: certFile},
+		"mismatched pair": {CertFile: certFile, KeyFile: empty},
+	} {
+		if _, err := Load(c); err == nil {
+			t.Errorf("%s: Load() error = nil", name)
+		}
+	}
+}
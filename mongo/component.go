@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 	"time"
 
+	kconfig "github.com/go-kratos/kratos/v2/config"
 	"github.com/nextmicro/logger"
+	"github.com/nextmicro/next-component/internal/connect"
 	"github.com/nextmicro/next-component/mongo/middleware"
 	"github.com/nextmicro/next-component/mongo/middleware/logging"
 	"github.com/nextmicro/next-component/mongo/middleware/metrics"
@@ -16,19 +19,30 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+	"golang.org/x/sync/singleflight"
 )
 
 var (
 	Mongo *Component
 	_     loader.Loader = &Component{}
+
+	// ErrInstanceNotFound 命名实例不存在
+	ErrInstanceNotFound = errors.New("instance not found")
+	// ErrNotConnected 延迟连接或非关键实例尚未建立连接
+	ErrNotConnected = errors.New("instance not connected")
 )
 
 type Component struct {
 	status        bool
+	mu            sync.RWMutex
+	reloadMu      sync.Mutex
 	defaultDBName string
 	options       []Option
 	opts          map[string]*Options
+	group         singleflight.Group
+	lazy          connect.Lazy // 延迟连接与非关键实例最近一次连接失败的原因
 	clients       sync.Map
+	drains        sync.Map // 热更新后等待断开的旧客户端 -> *time.Timer
 }
 
 func New(options ...Option) *Component {
@@ -56,26 +70,33 @@ func (c *Component) Init(opts ...loader.Option) error {
 			return errors.New("mongo: config not found")
 		}
 
-		for _, option := range cfg.Options() {
-			for _, opt := range c.opts {
-				option.apply(opt)
-			}
-		}
+		// WithConfig 的配置优先于 New 传入的 Option 应用，热更新时同样生效
+		c.options = append(cfg.Options(), c.options...)
 	}
 
-	for _, option := range c.options {
-		for _, opt := range c.opts {
-			option.apply(opt)
-		}
-	}
+	c.apply(c.opts)
 
 	if len(c.opts) == 0 {
 		return nil
 	}
 
+	// 默认实例延迟连接时，其他实例同样需要默认数据库名称
+	if opt, ok := c.opts[defaultName]; ok {
+		c.defaultDBName = opt.Database
+	}
+
 	for name, opt := range c.opts {
+		if opt.Lazy {
+			continue
+		}
+
 		client, err := c.connect(name, opt)
 		if err != nil {
+			if opt.Optional {
+				logger.Errorf("mongo: optional instance %s connect error: %v", name, err)
+				c.lazy.Fail(name, err)
+				continue
+			}
 			return err
 		}
 		Mongo.clients.Store(name, client)
@@ -87,18 +108,113 @@ func (c *Component) Init(opts ...loader.Option) error {
 	return nil
 }
 
+// Get 获取命名实例，name 为空时返回 default 实例，实例不存在时返回 ErrInstanceNotFound
+func (c *Component) Get(name string) (*Database, error) {
+	if name == "" {
+		name = defaultName
+	}
+
+	value, ok := c.clients.Load(name)
+	if !ok {
+		client, err := c.lazyConnect(name)
+		if err != nil {
+			return nil, err
+		}
+		value = client
+	}
+
+	c.mu.RLock()
+	dbName := c.defaultDBName
+	c.mu.RUnlock()
+
+	return value.(*Client).Database(dbName), nil
+}
+
+// MustGet 获取命名实例，实例不存在时 panic
+func (c *Component) MustGet(name string) *Database {
+	client, err := c.Get(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return client
+}
+
+// Instance 获取命名实例，等同于 MustGet，未传 name 时返回 default 实例
 func (c *Component) Instance(name ...string) *Database {
-	group := defaultName
-	if len(name) > 0 && name[0] != "" {
+	var group string
+	if len(name) > 0 {
 		group = name[0]
 	}
 
-	value, ok := c.clients.Load(group)
-	if !ok {
-		logger.Fatalf("mongo: client not found, group: %s", group)
+	return c.MustGet(group)
+}
+
+// Health 检查每个命名实例当前是否可用，尚未建立连接的实例返回包装 ErrNotConnected 的错误，
+// 其中非关键实例与尚未使用的延迟连接实例报告为 DEGRADED
+func (c *Component) Health(ctx context.Context) map[string]error {
+	ret := make(map[string]error)
+	c.clients.Range(func(key, value interface{}) bool {
+		ret[key.(string)] = value.(*Client).Ping(ctx, readpref.Primary())
+		return true
+	})
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for name, opt := range c.opts {
+		if _, ok := ret[name]; !ok {
+			ret[name] = c.lazy.Health(name, opt.Optional, fmt.Errorf("mongo: %w, group: %s", ErrNotConnected, name))
+		}
+	}
+	return ret
+}
+
+// lazyConnect 为延迟连接或启动时连接失败的非关键实例按需建立连接，
+// 通过 singleflight 保证同一实例并发获取时只建立一次连接。按需连接只尝试1次且限制时长，
+// 失败后在冷却期内直接返回上次的错误，避免请求阻塞在启动重试上。
+func (c *Component) lazyConnect(name string) (*Client, error) {
+	c.mu.RLock()
+	opt, ok := c.opts[name]
+	c.mu.RUnlock()
+	if !ok || !(opt.Lazy || opt.Optional) {
+		return nil, fmt.Errorf("mongo: %w, group: %s", ErrInstanceNotFound, name)
+	}
+	if err := c.lazy.Err(name); err != nil {
+		return nil, err
+	}
+
+	value, err, _ := c.group.Do(name, func() (interface{}, error) {
+		if value, ok := c.clients.Load(name); ok {
+			return value, nil
+		}
+
+		cfg := *opt
+		cfg.ConnectMaxAttempts, cfg.ConnectTimeout = 1, connect.OnceTimeout(opt.ConnectTimeout)
+		client, err := c.connect(name, &cfg)
+		if err != nil {
+			c.lazy.Fail(name, err)
+			return nil, err
+		}
+
+		c.lazy.Reset(name)
+
+		c.clients.Store(name, client)
+		return client, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return value.(*Client).Database(c.defaultDBName)
+	return value.(*Client), nil
+}
+
+// apply 将组件选项应用到每个命名实例的配置上
+func (c *Component) apply(opts map[string]*Options) {
+	for _, option := range c.options {
+		for _, opt := range opts {
+			option.apply(opt)
+		}
+	}
 }
 
 // buildDns build dns.
@@ -114,10 +230,9 @@ func (c *Component) buildDns(cfg *Options) string {
 	return dns
 }
 
-func (c *Component) connect(name string, cfg *Options) (*Client, error) {
-	if name == defaultName {
-		c.defaultDBName = cfg.Database
-	}
+func (c *Component) connect(name string, opt *Options) (*Client, error) {
+	// 在副本上补齐默认值，保持 c.opts 为原始配置以便热更新时比较差异
+	cfg := *opt
 	if cfg.DialTimeout == 0 {
 		cfg.DialTimeout = 300 * time.Second
 	}
@@ -135,7 +250,7 @@ func (c *Component) connect(name string, cfg *Options) (*Client, error) {
 		clientOpts.Monitor = otelmongo.NewMonitor()
 	}
 
-	clientOpts.ApplyURI(c.buildDns(cfg))
+	clientOpts.ApplyURI(c.buildDns(&cfg))
 
 	cc, err := Connect(context.Background(), clientOpts)
 	if err != nil {
@@ -165,11 +280,20 @@ func (c *Component) connect(name string, cfg *Options) (*Client, error) {
 
 	ms = append(ms, cfg.Middlewares...)
 	cc.middleware(ms)
-	err = cc.Ping(context.Background(), readpref.Primary())
+	err = retry(name, &cfg, func(ctx context.Context) error {
+		return cc.Ping(ctx, readpref.Primary())
+	})
 	if err != nil {
+		_ = cc.Disconnect(context.Background())
 		return nil, err
 	}
 
+	if name == defaultName {
+		c.mu.Lock()
+		c.defaultDBName = cfg.Database
+		c.mu.Unlock()
+	}
+
 	logger.Infof("%s %s connected success", namespace, name)
 	return cc, nil
 }
@@ -183,18 +307,156 @@ func (c *Component) Start(ctx context.Context) error {
 	return nil
 }
 
+// Watch 监听 mongo 配置变化，仅重建发生变化的实例
 func (c *Component) Watch() error {
-	return nil
+	if !c.status {
+		return nil
+	}
+
+	return config.Watch(namespace, c.watch)
+}
+
+func (c *Component) watch(_ string, value kconfig.Value) {
+	opts := make(map[string]*Options)
+	if err := value.Scan(&opts); err != nil {
+		logger.Errorf("mongo: watch scan config error: %v", err)
+		return
+	}
+
+	c.apply(opts)
+	c.reload(opts)
+}
+
+// reload 对比新旧配置，为变更或新增的实例重建客户端（重新应用中间件链）并原子替换，
+// 被替换或删除的旧客户端在未完成的操作结束后断开连接，未变更的实例保持原有连接。
+func (c *Component) reload(opts map[string]*Options) {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	for name, opt := range opts {
+		old, ok := c.opts[name]
+		if ok && equal(old, opt) {
+			continue
+		}
+		c.lazy.Reset(name)
+
+		// 延迟连接的实例只移除旧连接，下次获取时按新配置建立连接
+		if opt.Lazy {
+			if prev, loaded := c.clients.LoadAndDelete(name); loaded {
+				c.drain(name, prev.(*Client), opt.GracePeriod)
+			}
+			continue
+		}
+
+		client, err := c.connect(name, opt)
+		if err != nil {
+			logger.Errorf("mongo: reload %s error: %v", name, err)
+			if ok {
+				opts[name] = old
+			} else {
+				delete(opts, name)
+			}
+			continue
+		}
+
+		if prev, loaded := c.clients.Swap(name, client); loaded {
+			c.drain(name, prev.(*Client), opt.GracePeriod)
+		}
+		logger.Infof("%s %s reload success", namespace, name)
+	}
+
+	for name, old := range c.opts {
+		if _, ok := opts[name]; ok {
+			continue
+		}
+
+		if prev, loaded := c.clients.LoadAndDelete(name); loaded {
+			c.drain(name, prev.(*Client), old.GracePeriod)
+		}
+		c.lazy.Reset(name)
+		logger.Infof("%s %s removed", namespace, name)
+	}
+
+	c.mu.Lock()
+	c.opts = opts
+	c.mu.Unlock()
+}
+
+// drain 在宽限期后断开旧客户端，Disconnect 会等待正在使用的连接归还，最长等待一个宽限期
+func (c *Component) drain(name string, client *Client, grace time.Duration) {
+	if grace == 0 {
+		grace = defaultGracePeriod
+	}
+
+	timer := time.AfterFunc(grace, func() {
+		c.drains.Delete(client)
+		ctx, cancel := context.WithTimeout(context.Background(), grace)
+		defer cancel()
+
+		if err := client.Disconnect(ctx); err != nil {
+			logger.Errorf("mongo: disconnect %s old client error: %v", name, err)
+			return
+		}
+		logger.Infof("%s %s old client disconnected", namespace, name)
+	})
+	c.drains.Store(client, timer)
 }
 
+// equal 比较两份配置是否一致，中间件为函数无法比较，由组件选项统一注入，不参与比较
+func equal(a, b *Options) bool {
+	x, y := *a, *b
+	x.Middlewares, y.Middlewares = nil, nil
+	return reflect.DeepEqual(x, y)
+}
+
+// Stop 断开全部客户端（含热更新后尚在宽限期内的旧客户端），Disconnect 使用调用方的 ctx，
+// ctx 结束时不再等待并返回超时错误，断开失败的错误会被合并返回
 func (c *Component) Stop(ctx context.Context) error {
+	var clients []*Client
+	names := make(map[*Client]string)
 	c.clients.Range(func(key, value interface{}) bool {
 		client := value.(*Client)
-		_ = client.Disconnect(ctx)
+		clients = append(clients, client)
+		names[client] = key.(string)
+		c.clients.Delete(key)
+		return true
+	})
+	c.drains.Range(func(key, value interface{}) bool {
+		// 定时器已触发的旧客户端由定时器负责断开
+		if value.(*time.Timer).Stop() {
+			clients = append(clients, key.(*Client))
+		}
+		c.drains.Delete(key)
 		return true
 	})
 
-	c.clients = sync.Map{}
+	errc := make(chan error, len(clients))
+	for _, client := range clients {
+		go func(client *Client) {
+			if err := client.Disconnect(ctx); err != nil {
+				name, ok := names[client]
+				if !ok {
+					name = "old"
+				}
+				errc <- fmt.Errorf("mongo: disconnect %s %w", name, err)
+				return
+			}
+			errc <- nil
+		}(client)
+	}
+
+	var errs []error
+	for range clients {
+		select {
+		case err := <-errc:
+			errs = append(errs, err)
+		case <-ctx.Done():
+			return errors.Join(append(errs, fmt.Errorf("mongo: stop %w", ctx.Err()))...)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
 
 	logger.Infof("Component [%s] stop success", c.String())
 	return nil
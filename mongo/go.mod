@@ -9,9 +9,11 @@ require (
 	github.com/nextmicro/gokit/trace v1.0.7
 	github.com/nextmicro/logger v1.0.3
 	github.com/nextmicro/next v1.0.6
+	github.com/nextmicro/next-component/internal v0.0.0-00010101000000-000000000000
 	go.mongodb.org/mongo-driver v1.12.1
 	go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo v0.43.0
 	go.opentelemetry.io/otel v1.21.0
+	golang.org/x/sync v0.5.0
 )
 
 require (
@@ -74,7 +76,6 @@ require (
 	go.uber.org/zap v1.26.0 // indirect
 	golang.org/x/crypto v0.15.0 // indirect
 	golang.org/x/net v0.18.0 // indirect
-	golang.org/x/sync v0.5.0 // indirect
 	golang.org/x/sys v0.14.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 	golang.org/x/time v0.4.0 // indirect
@@ -86,3 +87,5 @@ require (
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/nextmicro/next-component/internal => ../internal
@@ -9,8 +9,9 @@ import (
 )
 
 const (
-	namespace   = "mongo"
-	defaultName = "default"
+	namespace          = "mongo"
+	defaultName        = "default"
+	defaultGracePeriod = 30 * time.Second
 )
 
 type Option interface {
@@ -37,7 +38,15 @@ type Options struct {
 	EnableLoggingRequest  bool                    `json:"enable_logging_request"`  // 是否开启记录请求参数
 	EnableLoggingResponse bool                    `json:"enable_logging_response"` // 是否开启记录响应参数
 	SlowThreshold         time.Duration           `json:"slow_threshold"`          // 慢日志门限值，超过该门限值的请求，将被记录到慢日志中
+	GracePeriod           time.Duration           `json:"grace_period"`            // 热更新后旧连接断开前的等待时间，默认30s
+	Lazy                  bool                    `json:"lazy"`                    // 延迟连接，首次获取实例时才建立连接
+	Optional              bool                    `json:"optional"`                // 非关键实例，启动时连接失败仅记录日志，不影响组件初始化
 	Middlewares           []middleware.Middleware `json:"-"`                       // 中间件
+
+	ConnectMaxAttempts int           `json:"connect_max_attempts"` // 建立连接的最大尝试次数，默认1次即不重试
+	ConnectMinBackoff  time.Duration `json:"connect_min_backoff"`  // 建立连接重试的初始间隔，默认1s，每次翻倍
+	ConnectMaxBackoff  time.Duration `json:"connect_max_backoff"`  // 建立连接重试的最大间隔，默认10s
+	ConnectTimeout     time.Duration `json:"connect_timeout"`      // 建立连接（含重试）的总时长，默认不限制
 }
 
 type mongoConfig struct{}
@@ -82,6 +91,21 @@ func (o *Options) Options() []Option {
 	if o.SlowThreshold != 0 {
 		opts = append(opts, WithSlowThreshold(o.SlowThreshold))
 	}
+	if o.GracePeriod != 0 {
+		opts = append(opts, WithGracePeriod(o.GracePeriod))
+	}
+	if o.Lazy {
+		opts = append(opts, WithLazy())
+	}
+	if o.Optional {
+		opts = append(opts, WithOptional())
+	}
+	if o.ConnectMaxAttempts != 0 || o.ConnectMinBackoff != 0 || o.ConnectMaxBackoff != 0 {
+		opts = append(opts, WithConnectRetry(o.ConnectMaxAttempts, o.ConnectMinBackoff, o.ConnectMaxBackoff))
+	}
+	if o.ConnectTimeout != 0 {
+		opts = append(opts, WithConnectTimeout(o.ConnectTimeout))
+	}
 	if o.DisableMetric {
 		opts = append(opts, WithDisableMetric())
 	}
@@ -160,6 +184,43 @@ func WithSlowThreshold(slowThreshold time.Duration) Option {
 	})
 }
 
+// WithGracePeriod 设置热更新后旧连接断开前的等待时间
+func WithGracePeriod(gracePeriod time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.GracePeriod = gracePeriod
+	})
+}
+
+// WithLazy 设置延迟连接
+func WithLazy() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Lazy = true
+	})
+}
+
+// WithOptional 设置为非关键实例
+func WithOptional() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Optional = true
+	})
+}
+
+// WithConnectRetry 设置建立连接的最大尝试次数与重试间隔
+func WithConnectRetry(maxAttempts int, minBackoff, maxBackoff time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.ConnectMaxAttempts = maxAttempts
+		cfg.ConnectMinBackoff = minBackoff
+		cfg.ConnectMaxBackoff = maxBackoff
+	})
+}
+
+// WithConnectTimeout 设置建立连接（含重试）的总时长
+func WithConnectTimeout(timeout time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.ConnectTimeout = timeout
+	})
+}
+
 // WithDisableMetric 设置禁用监控
 func WithDisableMetric() Option {
 	return OptionFunc(func(cfg *Options) {
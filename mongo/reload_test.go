@@ -0,0 +1,108 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// newTestClient 创建客户端，只启动后台监控，不要求 mongo 可用
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+
+	client, err := NewClient(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Disconnect(context.Background()) })
+	return client
+}
+
+func disconnected(client *Client) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	return errors.Is(client.Ping(ctx, nil), mongo.ErrClientDisconnected)
+}
+
+func drains(c *Component) int {
+	var n int
+	c.drains.Range(func(_, _ interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+func newReloadComponent(t *testing.T, grace time.Duration) (*Component, *Client, *Client) {
+	t.Helper()
+
+	c := New()
+	c.status = true
+	c.opts = map[string]*Options{
+		defaultName: {Address: "127.0.0.1:27017", GracePeriod: grace},
+		"removed":   {Address: "127.0.0.1:27018", GracePeriod: grace},
+	}
+	client, removed := newTestClient(t), newTestClient(t)
+	c.clients.Store(defaultName, client)
+	c.clients.Store("removed", removed)
+	return c, client, removed
+}
+
+func TestComponent_ReloadDrain(t *testing.T) {
+	c, client, removed := newReloadComponent(t, 20*time.Millisecond)
+
+	// 配置未变化时不替换
+	c.reload(map[string]*Options{
+		defaultName: {Address: "127.0.0.1:27017", GracePeriod: 20 * time.Millisecond},
+		"removed":   {Address: "127.0.0.1:27018", GracePeriod: 20 * time.Millisecond},
+	})
+	if got, _ := c.clients.Load(defaultName); got != client || drains(c) != 0 {
+		t.Fatalf("unchanged reload replaced client, drains = %d", drains(c))
+	}
+
+	// 变更为延迟连接的实例与被删除的实例在宽限期后断开
+	c.reload(map[string]*Options{
+		defaultName: {Address: "127.0.0.1:27019", Lazy: true, GracePeriod: 20 * time.Millisecond},
+	})
+	for _, name := range []string{defaultName, "removed"} {
+		if _, ok := c.clients.Load(name); ok {
+			t.Fatalf("instance %s still holds the old client", name)
+		}
+	}
+	if n := drains(c); n != 2 || disconnected(client) || disconnected(removed) {
+		t.Fatalf("drains = %d, want 2 pending drains before the grace period", n)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !(disconnected(client) && disconnected(removed)) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !disconnected(client) || !disconnected(removed) {
+		t.Fatal("old clients not disconnected after the grace period")
+	}
+}
+
+func TestComponent_StopDisconnectsDrains(t *testing.T) {
+	c, client, removed := newReloadComponent(t, time.Hour)
+
+	c.reload(map[string]*Options{
+		defaultName: {Address: "127.0.0.1:27017", GracePeriod: time.Hour},
+	})
+	if n := drains(c); n != 1 {
+		t.Fatalf("drains = %d, want 1", n)
+	}
+
+	if err := c.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if !disconnected(client) || !disconnected(removed) {
+		t.Fatal("Stop() did not disconnect active and draining clients")
+	}
+	if n := drains(c); n != 0 {
+		t.Fatalf("drains = %d after Stop, want 0", n)
+	}
+}
@@ -15,54 +15,128 @@ import (
 var (
 	Nsq *Component
 	_   loader.Loader = &Component{}
+
+	// ErrInstanceNotFound 命名实例不存在
+	ErrInstanceNotFound = errors.New("instance not found")
+	// ErrProducerClosed 组件停止后发布消息
+	ErrProducerClosed = errors.New("nsq: producer closed")
 )
 
 type Component struct {
-	opts     map[string]*Options
-	open     bool
-	config   *nsq.Config
-	options  []Option
-	producer sync.Map
-	consumer sync.Map
+	ctx         context.Context
+	cancelFn    func()
+	opts        map[string]*Options
+	open        bool
+	started     bool
+	options     []Option
+	producer    sync.Map
+	consumer    sync.Map
+	subscribers sync.Map
 }
 
 func New(options ...Option) *Component {
+	ctx, cancel := context.WithCancel(context.Background())
 	Nsq = &Component{
-		options: options,
-		config:  nsq.NewConfig(),
-		opts:    make(map[string]*Options),
+		ctx:      ctx,
+		cancelFn: cancel,
+		options:  options,
+		opts:     make(map[string]*Options),
 	}
 	return Nsq
 }
 
-// Producer 获取生产者
+// GetProducer 获取生产者，name 为空时返回 default 实例，实例不存在时返回 ErrInstanceNotFound，
+// 配置了多个 nsqd 时返回第一个，需要按发布策略发布时使用 GetPublisher
+func (c *Component) GetProducer(name string) (*nsq.Producer, error) {
+	p, err := c.GetPublisher(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.pool.producers[0], nil
+}
+
+// MustGetProducer 获取生产者，实例不存在时 panic
+func (c *Component) MustGetProducer(name string) *nsq.Producer {
+	p, err := c.GetProducer(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// Producer 获取生产者，等同于 MustGetProducer，未传 name 时返回 default 实例
 func (c *Component) Producer(name ...string) *nsq.Producer {
-	group := defaultName
-	if len(name) > 0 && name[0] != "" {
+	var group string
+	if len(name) > 0 {
 		group = name[0]
 	}
 
-	value, ok := c.producer.Load(group)
+	return c.MustGetProducer(group)
+}
+
+// GetConsumer 获取消费者，name 为空时返回 default 实例，实例不存在时返回 ErrInstanceNotFound
+func (c *Component) GetConsumer(name string) (*nsq.Consumer, error) {
+	if name == "" {
+		name = defaultName
+	}
+
+	value, ok := c.consumer.Load(name)
 	if !ok {
-		logger.Fatalf("nsq: producer not found, group: %s", group)
+		return nil, fmt.Errorf("nsq: consumer %w, group: %s", ErrInstanceNotFound, name)
 	}
 
-	return value.(*nsq.Producer)
+	return value.(*nsq.Consumer), nil
 }
 
-// NewConsumer 获取消费者
+// MustGetConsumer 获取消费者，实例不存在时 panic
+func (c *Component) MustGetConsumer(name string) *nsq.Consumer {
+	cm, err := c.GetConsumer(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return cm
+}
+
+// NewConsumer 获取消费者，等同于 MustGetConsumer，未传 name 时返回 default 实例
 func (c *Component) NewConsumer(name ...string) *nsq.Consumer {
-	group := defaultName
-	if len(name) > 0 && name[0] != "" {
+	var group string
+	if len(name) > 0 {
 		group = name[0]
 	}
 
-	value, ok := c.producer.Load(group)
-	if !ok {
-		logger.Fatalf("nsq: consumer not found, group: %s", group)
-	}
+	return c.MustGetConsumer(group)
+}
+
+// Health 检查每个命名实例当前是否可用，生产者通过 Ping 检查，消费者检查是否存在可用连接
+func (c *Component) Health(ctx context.Context) map[string]error {
+	ret := make(map[string]error)
+	c.producer.Range(func(key, value interface{}) bool {
+		var errs []error
+		for _, p := range value.(*producerPool).producers {
+			if err := p.Ping(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", p.String(), err))
+			}
+		}
+		ret[key.(string)] = errors.Join(errs...)
+		return true
+	})
+	c.consumer.Range(func(key, value interface{}) bool {
+		// 未注册处理函数的消费者不会建立连接
+		if _, ok := c.subscribers.Load(key); !ok || !c.started {
+			return true
+		}
 
-	return value.(*nsq.Consumer)
+		var err error
+		if value.(*nsq.Consumer).Stats().Connections == 0 {
+			err = fmt.Errorf("nsq: consumer %s has no connections", key)
+		}
+		ret[key.(string)] = errors.Join(ret[key.(string)], err)
+		return true
+	})
+	return ret
 }
 
 func (c *Component) Init(opts ...loader.Option) error {
@@ -100,26 +174,26 @@ func (c *Component) Init(opts ...loader.Option) error {
 	}
 
 	for name, opt := range c.opts {
+		if opt.codec() == nil {
+			return fmt.Errorf("nsq: %s codec %q not found", name, opt.Codec)
+		}
+		cfg, err := newConfig(opt)
+		if err != nil {
+			return fmt.Errorf("nsq: %s config %w", name, err)
+		}
 		if opt.Producer != nil {
-			p, err := nsq.NewProducer(opt.Producer.Addr, c.config)
+			pool, err := newProducerPool(opt.Producer, cfg)
 			if err != nil {
-				return fmt.Errorf("nsq: NewProducer %w", err)
-			}
-			if err = p.Ping(); err != nil {
-				return fmt.Errorf("nsq: Ping error %w", err)
+				return fmt.Errorf("nsq: producer %s %w", name, err)
 			}
-			c.producer.Store(name, p)
+			c.producer.Store(name, pool)
 		}
 		if opt.Consumer != nil {
-			cm, err := nsq.NewConsumer(opt.Consumer.Topic, opt.Consumer.Channel, c.config)
+			// 消费者在 Subscribe 注册处理函数后，于 Start 时再连接 nsqlookupd
+			cm, err := nsq.NewConsumer(opt.Consumer.Topic, opt.Consumer.Channel, cfg)
 			if err != nil {
 				return fmt.Errorf("nsq: NewConsumer %w", err)
 			}
-
-			err = cm.ConnectToNSQLookupd(opt.Consumer.Addr)
-			if err != nil {
-				return fmt.Errorf("nsq: ConnectToNSQLookupd %w", err)
-			}
 			c.consumer.Store(name, cm)
 		}
 	}
@@ -130,6 +204,11 @@ func (c *Component) Init(opts ...loader.Option) error {
 }
 
 func (c *Component) Start(ctx context.Context) error {
+	if err := c.startConsumers(); err != nil {
+		return err
+	}
+	c.started = true
+
 	logger.Infof("Component [%s] Start success", c.String())
 	return nil
 }
@@ -138,22 +217,23 @@ func (c *Component) Watch() error {
 	return nil
 }
 
+// Stop 优雅关闭：先停止消费者并等待处理中的消息完成，再等待异步发布完成后停止生产者，
+// ctx 结束时不再等待并返回超时错误
 func (c *Component) Stop(ctx context.Context) error {
-	// stop the producers
-	c.producer.Range(func(key, value interface{}) bool {
-		client := value.(*nsq.Producer)
-		_ = client.Stop
-		return true
-	})
-	c.producer = sync.Map{}
+	// 处理中的消息可能仍需发布消息或投递死信，因此先停止消费者
+	err := c.stopConsumers(ctx)
+	err = errors.Join(err, c.stopProducers(ctx))
 
-	// stop the consumers
-	c.consumer.Range(func(key, value interface{}) bool {
-		client := value.(*nsq.Consumer)
-		_ = client.Stop
-		return true
-	})
+	c.producer = sync.Map{}
 	c.consumer = sync.Map{}
+	c.subscribers = sync.Map{}
+	c.started = false
+	if c.cancelFn != nil {
+		c.cancelFn()
+	}
+	if err != nil {
+		return err
+	}
 
 	logger.Infof("Component [%s] stop success", c.String())
 	return nil
@@ -162,3 +242,19 @@ func (c *Component) Stop(ctx context.Context) error {
 func (c *Component) String() string {
 	return namespace
 }
+
+// wait 等待 fn 执行完成，ctx 先结束时返回 ctx.Err()，fn 仍在后台继续执行
+func wait(ctx context.Context, fn func()) error {
+	done := make(chan struct{})
+	go func() {
+		fn()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,52 @@
+package nsq_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	kconfig "github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+	"github.com/nextmicro/next-component/nsq"
+	"github.com/nextmicro/next/config"
+)
+
+func loadConfig(t *testing.T, content string) {
+	filename := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := kconfig.New(kconfig.WithSource(file.NewSource(filename)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	config.DefaultConfig = c
+	t.Cleanup(func() { _ = c.Close() })
+}
+
+func TestComponent_Subscribe(t *testing.T) {
+	loadConfig(t, `{"nsq":{"default":{"consumer":{"addr":"127.0.0.1:4161","topic":"test","channel":"ch"}}}}`)
+
+	c := nsq.New()
+	if err := c.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := func(ctx context.Context, msg *nsq.Message) error { return nil }
+	if err := c.Subscribe("missing", handler, 1); !errors.Is(err, nsq.ErrInstanceNotFound) {
+		t.Fatalf("expected ErrInstanceNotFound, got %v", err)
+	}
+	if err := c.Subscribe("", handler, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Subscribe("default", handler, 1); err == nil {
+		t.Fatal("expected duplicate subscribe error")
+	}
+
+	if err := c.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
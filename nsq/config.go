@@ -0,0 +1,49 @@
+package nsq
+
+import (
+	"github.com/nextmicro/next-component/internal/tlsconfig"
+	nsq "github.com/nsqio/go-nsq"
+)
+
+// newConfig 根据实例配置生成 nsq.Config，未设置的字段使用 go-nsq 默认值
+func newConfig(opt *Options) (*nsq.Config, error) {
+	cfg := nsq.NewConfig()
+	if opt.AuthSecret != "" {
+		cfg.AuthSecret = opt.AuthSecret
+	}
+	if opt.MaxInFlight > 0 {
+		cfg.MaxInFlight = opt.MaxInFlight
+	}
+	if opt.HeartbeatInterval > 0 {
+		cfg.HeartbeatInterval = opt.HeartbeatInterval
+	}
+	if opt.DialTimeout > 0 {
+		cfg.DialTimeout = opt.DialTimeout
+	}
+	if opt.ReadTimeout > 0 {
+		cfg.ReadTimeout = opt.ReadTimeout
+	}
+	if opt.WriteTimeout > 0 {
+		cfg.WriteTimeout = opt.WriteTimeout
+	}
+	if opt.MsgTimeout > 0 {
+		cfg.MsgTimeout = opt.MsgTimeout
+	}
+	if opt.Consumer != nil && opt.Consumer.MaxAttempts > 0 {
+		// 超过 MaxAttempts 的消息会被 go-nsq 直接丢弃，需与重试策略一致才能投递到死信主题
+		cfg.MaxAttempts = opt.Consumer.MaxAttempts
+	}
+	if opt.TLS != nil {
+		tlsConfig, err := tlsconfig.Load(opt.TLS)
+		if err != nil {
+			return nil, err
+		}
+		cfg.TlsV1 = true
+		cfg.TlsConfig = tlsConfig
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,205 @@
+package nsq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	prom "github.com/go-kratos/kratos/contrib/metrics/prometheus/v2"
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/nextmicro/gokit/timex"
+	"github.com/nextmicro/logger"
+	"github.com/nextmicro/next-component/internal/envelope"
+	"github.com/nextmicro/next/pkg/metrics"
+	nsq "github.com/nsqio/go-nsq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	consumerRequests = prom.NewCounter(metrics.MessagingConsumerMetricRequests)
+	consumerSeconds  = prom.NewHistogram(metrics.MessagingConsumerMetricMillisecond)
+)
+
+// Message 消费到的消息，内嵌 *nsq.Message，可直接调用 Touch、Requeue 等方法
+type Message struct {
+	*nsq.Message
+	Topic   string            // 主题
+	Channel string            // 频道
+	Header  map[string]string // 消息信封中的 header，如死信消息的失败原因
+
+	codec encoding.Codec
+}
+
+// Decode 按实例配置的 codec 解码消息体
+func (m *Message) Decode(v interface{}) error {
+	return m.codec.Unmarshal(m.Body, v)
+}
+
+// Handler 消息处理函数，返回 error 时消息将被重新入队，配置了重试策略时按策略重试或投递到死信主题
+type Handler func(ctx context.Context, msg *Message) error
+
+type subscriber struct {
+	handler     Handler
+	concurrency int
+}
+
+// Subscribe 为命名消费者注册处理函数，concurrency 为并发处理的协程数，
+// 必须在 Start 之前调用，消费者在 Start 时才会连接 nsqlookupd 开始消费。
+func (c *Component) Subscribe(name string, handler Handler, concurrency int) error {
+	if name == "" {
+		name = defaultName
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	cm, err := c.GetConsumer(name)
+	if err != nil {
+		return err
+	}
+	opt := c.opts[name]
+
+	var dlq *Publisher
+	if opt.Consumer.DeadLetterTopic != "" {
+		producer := opt.Consumer.DeadLetterProducer
+		if producer == "" {
+			producer = name
+		}
+		if dlq, err = c.GetPublisher(producer); err != nil {
+			return fmt.Errorf("nsq: consumer %s dead letter %w", name, err)
+		}
+	}
+	if c.started {
+		return fmt.Errorf("nsq: consumer %s subscribe after start", name)
+	}
+	if _, loaded := c.subscribers.LoadOrStore(name, &subscriber{handler: handler, concurrency: concurrency}); loaded {
+		return fmt.Errorf("nsq: consumer %s already subscribed", name)
+	}
+
+	cm.AddConcurrentHandlers(c.handle(name, opt, dlq, handler), concurrency)
+	// max_in_flight 默认为1，需不小于并发数才能让每个处理协程都拿到消息
+	cm.ChangeMaxInFlight(max(concurrency, opt.MaxInFlight))
+	return nil
+}
+
+// handle 解开消息信封，按实例配置记录链路、监控与日志后交给处理函数，处理失败时执行重试策略
+func (c *Component) handle(name string, opt *Options, dlq *Publisher, handler Handler) nsq.HandlerFunc {
+	topic, channel := opt.Consumer.Topic, opt.Consumer.Channel
+	return func(m *nsq.Message) (err error) {
+		start := time.Now()
+		headers, body := envelope.Decode(m.Body)
+		m.Body = body
+
+		ctx := c.ctx
+		if !opt.DisableTrace {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+			var span trace.Span
+			ctx, span = tracer.Start(ctx, topic+" process",
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					semconv.MessagingSystemKey.String(kind),
+					semconv.MessagingDestinationName(topic),
+					semconv.MessagingOperationProcess,
+					semconv.MessagingMessageID(string(m.ID[:])),
+				),
+			)
+			defer func() {
+				if err != nil {
+					span.RecordError(err)
+					span.SetStatus(codes.Error, err.Error())
+				}
+				span.End()
+			}()
+		}
+
+		msg := &Message{
+			Message: m,
+			Topic:   topic,
+			Channel: channel,
+			Header:  headers,
+			codec:   opt.codec(),
+		}
+		err = handler(ctx, msg)
+		duration := time.Since(start)
+
+		if !opt.DisableMetric {
+			code := codes.Ok
+			if err != nil {
+				code = codes.Error
+			}
+			consumerRequests.With(kind, m.NSQDAddress, topic, channel, code.String()).Inc()
+			consumerSeconds.With(kind, m.NSQDAddress, topic, channel).Observe(float64(duration.Milliseconds()))
+		}
+
+		if !opt.DisableLogging {
+			fields := map[string]interface{}{
+				"kind":      "mq",
+				"component": kind,
+				"name":      name,
+				"addr":      m.NSQDAddress,
+				"topic":     topic,
+				"channel":   channel,
+				"attempts":  m.Attempts,
+				"duration":  timex.Duration(duration),
+			}
+			log := logger.WithContext(ctx)
+			if err != nil {
+				fields["error"] = err
+				log.WithFields(fields).Error("nsq consumer")
+			} else {
+				log.WithFields(fields).Info("nsq consumer")
+			}
+		}
+
+		if err != nil && opt.Consumer.MaxAttempts > 0 {
+			c.fail(ctx, name, opt, dlq, msg, err)
+		}
+		return err
+	}
+}
+
+// startConsumers 已注册处理函数的消费者连接 nsqlookupd 开始消费
+func (c *Component) startConsumers() (err error) {
+	c.consumer.Range(func(key, value interface{}) bool {
+		name := key.(string)
+		if _, ok := c.subscribers.Load(name); !ok {
+			logger.Warnf("nsq: consumer %s has no handler, skip", name)
+			return true
+		}
+
+		if err = value.(*nsq.Consumer).ConnectToNSQLookupd(c.opts[name].Consumer.Addr); err != nil {
+			err = fmt.Errorf("nsq: consumer %s ConnectToNSQLookupd %w", name, err)
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+// stopConsumers 停止消费并等待正在处理的消息完成
+func (c *Component) stopConsumers(ctx context.Context) error {
+	var wg sync.WaitGroup
+	c.consumer.Range(func(key, value interface{}) bool {
+		cm := value.(*nsq.Consumer)
+		cm.Stop()
+		// 未注册处理函数的消费者不会关闭 StopChan
+		if _, ok := c.subscribers.Load(key); ok {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-cm.StopChan
+			}()
+		}
+		return true
+	})
+
+	if err := wait(ctx, wg.Wait); err != nil {
+		return fmt.Errorf("nsq: stop consumers %w", err)
+	}
+	return nil
+}
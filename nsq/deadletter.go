@@ -0,0 +1,71 @@
+package nsq
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/nextmicro/logger"
+)
+
+// 死信消息信封中的 header，消费死信主题时可通过 Message.Header 读取
+const (
+	HeaderDeadLetterTopic    = "x-dead-letter-topic"    // 原主题
+	HeaderDeadLetterChannel  = "x-dead-letter-channel"  // 原频道
+	HeaderDeadLetterAttempts = "x-dead-letter-attempts" // 投递次数
+	HeaderDeadLetterError    = "x-dead-letter-error"    // 最后一次处理失败的原因
+)
+
+// fail 按重试策略处理失败的消息：未达到最大投递次数时按指数退避重新入队，
+// 达到后投递到死信主题并结束消息，未配置死信主题时直接丢弃，死信投递失败时重新入队。
+// 监控计入 consumerRequests，status 为 retry、published（投递到死信主题）、dropped
+func (c *Component) fail(ctx context.Context, name string, opt *Options, dlq *Publisher, msg *Message, cause error) {
+	m, cfg := msg.Message, opt.Consumer
+	// 处理函数已自行响应的消息不再处理
+	if m.HasResponded() {
+		return
+	}
+	m.DisableAutoResponse()
+
+	if m.Attempts < cfg.MaxAttempts {
+		if !opt.DisableMetric {
+			consumerRequests.With(kind, m.NSQDAddress, cfg.Topic, cfg.Channel, "retry").Inc()
+		}
+		m.RequeueWithoutBackoff(cfg.requeueDelay(m.Attempts))
+		return
+	}
+
+	fields := map[string]interface{}{
+		"kind":      "mq",
+		"component": kind,
+		"name":      name,
+		"topic":     cfg.Topic,
+		"channel":   cfg.Channel,
+		"attempts":  m.Attempts,
+		"error":     cause,
+	}
+	log := logger.WithContext(ctx)
+
+	status := "dropped"
+	if dlq != nil {
+		header := map[string]string{
+			HeaderDeadLetterTopic:    cfg.Topic,
+			HeaderDeadLetterChannel:  cfg.Channel,
+			HeaderDeadLetterAttempts: strconv.Itoa(int(m.Attempts)),
+			HeaderDeadLetterError:    cause.Error(),
+		}
+		if err := dlq.publishWithHeader(ctx, cfg.DeadLetterTopic, header, m.Body); err != nil {
+			fields["dead_letter_error"] = err
+			log.WithFields(fields).Error("nsq consumer dead letter failed, requeue")
+			m.RequeueWithoutBackoff(cfg.requeueDelay(m.Attempts))
+			return
+		}
+		status = "published"
+		fields["dead_letter_topic"] = cfg.DeadLetterTopic
+	}
+
+	if !opt.DisableMetric {
+		consumerRequests.With(kind, m.NSQDAddress, cfg.Topic, cfg.Channel, status).Inc()
+	}
+	log.WithFields(fields).Warn("nsq consumer retry exhausted, " + status)
+	m.Finish()
+}
@@ -0,0 +1,283 @@
+package nsq
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextmicro/next-component/internal/envelope"
+	nsq "github.com/nsqio/go-nsq"
+)
+
+func TestConsumer_RequeueDelay(t *testing.T) {
+	c := &Consumer{RequeueDelay: time.Second, MaxRequeueDelay: 5 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, d := range want {
+		if got := c.requeueDelay(uint16(i + 1)); got != d {
+			t.Fatalf("attempts %d: requeueDelay = %v, want %v", i+1, got, d)
+		}
+	}
+
+	if got := (&Consumer{}).requeueDelay(1); got != defaultRequeueDelay {
+		t.Fatalf("default requeueDelay = %v", got)
+	}
+}
+
+// fakeDelegate 记录消息的响应
+type fakeDelegate struct {
+	finished int
+	requeued []time.Duration
+	backoff  bool
+}
+
+func (d *fakeDelegate) OnFinish(*nsq.Message) { d.finished++ }
+
+func (d *fakeDelegate) OnRequeue(_ *nsq.Message, delay time.Duration, backoff bool) {
+	d.requeued = append(d.requeued, delay)
+	d.backoff = d.backoff || backoff
+}
+
+func (d *fakeDelegate) OnTouch(*nsq.Message) {}
+
+func newTestMessage(attempts uint16, body []byte) (*nsq.Message, *fakeDelegate) {
+	d := &fakeDelegate{}
+	m := nsq.NewMessage(nsq.MessageID{'1'}, body)
+	m.Attempts = attempts
+	m.NSQDAddress = "127.0.0.1:4150"
+	m.Delegate = d
+	return m, d
+}
+
+type published struct {
+	topic string
+	body  []byte
+}
+
+// fakeNSQD 只实现生产者所需协议的 nsqd，记录收到的 PUB
+func fakeNSQD(t *testing.T) (string, <-chan published) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan published, 10)
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		_ = l.Close()
+		wg.Wait()
+	})
+
+	respond := func(w io.Writer, data string) error {
+		frame := make([]byte, 8+len(data))
+		binary.BigEndian.PutUint32(frame, uint32(4+len(data)))
+		binary.BigEndian.PutUint32(frame[4:], uint32(nsq.FrameTypeResponse))
+		copy(frame[8:], data)
+		_, err := w.Write(frame)
+		return err
+	}
+	serve := func(conn net.Conn) {
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		if _, err := io.ReadFull(r, make([]byte, len(nsq.MagicV2))); err != nil {
+			return
+		}
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			params := strings.Fields(line)
+			var body []byte
+			if len(params) > 0 && (params[0] == "IDENTIFY" || params[0] == "PUB") {
+				var size int32
+				if err = binary.Read(r, binary.BigEndian, &size); err != nil {
+					return
+				}
+				body = make([]byte, size)
+				if _, err = io.ReadFull(r, body); err != nil {
+					return
+				}
+			}
+			if len(params) == 2 && params[0] == "PUB" {
+				ch <- published{topic: params[1], body: body}
+			}
+			if err = respond(conn, "OK"); err != nil {
+				return
+			}
+		}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				serve(conn)
+			}()
+		}
+	}()
+	return l.Addr().String(), ch
+}
+
+func newTestPublisher(t *testing.T, addr string) *Publisher {
+	t.Helper()
+
+	p, err := nsq.NewProducer(addr, nsq.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetLogger(nil, nsq.LogLevelError)
+	t.Cleanup(p.Stop)
+
+	opt := &Options{DisableMetric: true, DisableTrace: true, DisableLogging: true}
+	return &Publisher{name: "dlq", opt: opt, codec: opt.codec(), pool: &producerPool{producers: []*nsq.Producer{p}}}
+}
+
+func newFailOptions(deadLetterTopic string) *Options {
+	return &Options{
+		Consumer: &Consumer{
+			Topic:           "orders",
+			Channel:         "billing",
+			MaxAttempts:     3,
+			RequeueDelay:    time.Second,
+			MaxRequeueDelay: time.Minute,
+			DeadLetterTopic: deadLetterTopic,
+		},
+		DisableMetric:  true,
+		DisableTrace:   true,
+		DisableLogging: true,
+	}
+}
+
+func TestComponent_HandleRetry(t *testing.T) {
+	c := New()
+	opt := newFailOptions("")
+	cause := errors.New("handle failed")
+
+	for attempts := uint16(1); attempts < 3; attempts++ {
+		body, err := envelope.Encode(map[string]string{"x-request-id": "1"}, []byte("body"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, d := newTestMessage(attempts, body)
+
+		var got *Message
+		err = c.handle(defaultName, opt, nil, func(_ context.Context, msg *Message) error {
+			got = msg
+			return cause
+		})(m)
+		if !errors.Is(err, cause) {
+			t.Fatalf("handle() error = %v, want %v", err, cause)
+		}
+		if string(got.Body) != "body" || got.Header["x-request-id"] != "1" {
+			t.Fatalf("handler message = %q %v, want unwrapped envelope", got.Body, got.Header)
+		}
+
+		// 未达到最大投递次数时按退避时间重新入队
+		want := opt.Consumer.requeueDelay(attempts)
+		if len(d.requeued) != 1 || d.requeued[0] != want || d.backoff || d.finished != 0 {
+			t.Fatalf("attempts %d: requeued %v backoff %v finished %d, want requeue after %v",
+				attempts, d.requeued, d.backoff, d.finished, want)
+		}
+	}
+
+	// 处理成功时不重试，由 nsq 自动确认
+	m, d := newTestMessage(1, []byte("body"))
+	if err := c.handle(defaultName, opt, nil, func(context.Context, *Message) error { return nil })(m); err != nil {
+		t.Fatalf("handle() error = %v", err)
+	}
+	if len(d.requeued) != 0 || d.finished != 0 {
+		t.Fatalf("requeued %v finished %d, want untouched", d.requeued, d.finished)
+	}
+}
+
+func TestComponent_FailDeadLetter(t *testing.T) {
+	addr, pubs := fakeNSQD(t)
+	c := New()
+	opt := newFailOptions("orders.dlq")
+
+	m, d := newTestMessage(3, []byte("body"))
+	msg := &Message{Message: m, Topic: "orders", Channel: "billing"}
+	c.fail(context.Background(), defaultName, opt, newTestPublisher(t, addr), msg, errors.New("handle failed"))
+
+	// 达到最大投递次数时投递到死信主题并结束消息
+	if d.finished != 1 || len(d.requeued) != 0 {
+		t.Fatalf("finished %d requeued %v, want finished", d.finished, d.requeued)
+	}
+	select {
+	case p := <-pubs:
+		header, body := envelope.Decode(p.body)
+		want := map[string]string{
+			HeaderDeadLetterTopic:    "orders",
+			HeaderDeadLetterChannel:  "billing",
+			HeaderDeadLetterAttempts: "3",
+			HeaderDeadLetterError:    "handle failed",
+		}
+		if p.topic != "orders.dlq" || string(body) != "body" {
+			t.Fatalf("dead letter = %s %q, want orders.dlq body", p.topic, body)
+		}
+		for k, v := range want {
+			if header[k] != v {
+				t.Fatalf("dead letter header %s = %q, want %q", k, header[k], v)
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatal("dead letter not published")
+	}
+}
+
+func TestComponent_FailDrop(t *testing.T) {
+	c := New()
+	m, d := newTestMessage(3, []byte("body"))
+	c.fail(context.Background(), defaultName, newFailOptions(""), nil, &Message{Message: m}, errors.New("handle failed"))
+
+	// 未配置死信主题时丢弃
+	if d.finished != 1 || len(d.requeued) != 0 {
+		t.Fatalf("finished %d requeued %v, want dropped", d.finished, d.requeued)
+	}
+}
+
+func TestComponent_FailDeadLetterError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+
+	c := New()
+	opt := newFailOptions("orders.dlq")
+	m, d := newTestMessage(3, []byte("body"))
+	c.fail(context.Background(), defaultName, opt, newTestPublisher(t, addr), &Message{Message: m}, errors.New("handle failed"))
+
+	// 死信投递失败时重新入队，不丢失消息
+	if want := opt.Consumer.requeueDelay(3); len(d.requeued) != 1 || d.requeued[0] != want || d.finished != 0 {
+		t.Fatalf("requeued %v finished %d, want requeue after %v", d.requeued, d.finished, want)
+	}
+}
+
+func TestComponent_FailResponded(t *testing.T) {
+	c := New()
+	m, d := newTestMessage(3, []byte("body"))
+	m.Finish()
+	c.fail(context.Background(), defaultName, newFailOptions(""), nil, &Message{Message: m}, errors.New("handle failed"))
+
+	// 处理函数已响应的消息不再处理
+	if d.finished != 1 || len(d.requeued) != 0 {
+		t.Fatalf("finished %d requeued %v, want only the handler response", d.finished, d.requeued)
+	}
+}
@@ -3,9 +3,16 @@ module github.com/nextmicro/next-component/nsq
 go 1.21.0
 
 require (
+	github.com/go-kratos/kratos/contrib/metrics/prometheus/v2 v2.0.0-20231116090954-1e4e37ad8735
+	github.com/go-kratos/kratos/v2 v2.7.2-0.20231113102135-421dbc7dae0f
+	github.com/nextmicro/gokit/timex v1.0.0
 	github.com/nextmicro/logger v1.0.3
 	github.com/nextmicro/next v1.0.6
+	github.com/nextmicro/next-component/internal v0.0.0-00010101000000-000000000000
 	github.com/nsqio/go-nsq v1.1.0
+	github.com/prometheus/client_golang v1.17.0
+	go.opentelemetry.io/otel v1.21.0
+	go.opentelemetry.io/otel/trace v1.21.0
 )
 
 require (
@@ -25,7 +32,8 @@ require (
 	github.com/buger/jsonparser v1.1.1 // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/fsnotify/fsnotify v1.7.0 // indirect
-	github.com/go-kratos/kratos/v2 v2.7.2-0.20231113102135-421dbc7dae0f // indirect
+	github.com/go-logr/logr v1.3.0 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/golang/mock v1.6.0 // indirect
 	github.com/golang/protobuf v1.5.3 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
@@ -40,13 +48,11 @@ require (
 	github.com/nacos-group/nacos-sdk-go/v2 v2.2.4 // indirect
 	github.com/opentracing/opentracing-go v1.2.1-0.20220228012449-10b1cf09e00b // indirect
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/prometheus/client_golang v1.17.0 // indirect
 	github.com/prometheus/client_model v0.5.0 // indirect
 	github.com/prometheus/common v0.45.0 // indirect
 	github.com/prometheus/procfs v0.12.0 // indirect
 	github.com/tjfoc/gmsm v1.4.1 // indirect
-	go.opentelemetry.io/otel v1.21.0 // indirect
-	go.opentelemetry.io/otel/trace v1.21.0 // indirect
+	go.opentelemetry.io/otel/metric v1.21.0 // indirect
 	go.uber.org/goleak v1.3.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	go.uber.org/zap v1.26.0 // indirect
@@ -63,3 +69,5 @@ require (
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/nextmicro/next-component/internal => ../internal
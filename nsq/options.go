@@ -2,13 +2,27 @@ package nsq
 
 import (
 	"context"
+	"time"
 
+	"github.com/go-kratos/kratos/v2/encoding"
+	_ "github.com/go-kratos/kratos/v2/encoding/json"
+	_ "github.com/go-kratos/kratos/v2/encoding/proto"
+	"github.com/nextmicro/next-component/internal/tlsconfig"
 	"github.com/nextmicro/next/runtime/loader"
 )
 
 const (
-	namespace   = "nsq"
-	defaultName = "default"
+	namespace              = "nsq"
+	defaultName            = "default"
+	defaultCodec           = "json"
+	defaultRequeueDelay    = time.Second
+	defaultMaxRequeueDelay = time.Minute
+)
+
+// 多个 nsqd 时的发布策略
+const (
+	StrategyFailover   = "failover"
+	StrategyRoundRobin = "round_robin"
 )
 
 type Option interface {
@@ -22,18 +36,49 @@ func (fn OptionFunc) apply(cfg *Options) {
 }
 
 type Options struct {
-	Producer *Producer `json:"producer"` // 生产者配置
-	Consumer *Consumer `json:"consumer"` // 消费者配置
+	Producer          *Producer     `json:"producer"`           // 生产者配置
+	Consumer          *Consumer     `json:"consumer"`           // 消费者配置
+	AuthSecret        string        `json:"auth_secret"`        // nsqd 鉴权密钥
+	TLS               *TLS          `json:"tls"`                // TLS 配置，为空时不启用
+	MaxInFlight       int           `json:"max_in_flight"`      // 消费者最大未确认消息数，默认1，Subscribe 时不小于并发数
+	HeartbeatInterval time.Duration `json:"heartbeat_interval"` // 心跳间隔，默认30s，需小于 ReadTimeout
+	DialTimeout       time.Duration `json:"dial_timeout"`       // 拨号超时，默认1s
+	ReadTimeout       time.Duration `json:"read_timeout"`       // 读超时，默认60s
+	WriteTimeout      time.Duration `json:"write_timeout"`      // 写超时，默认1s
+	MsgTimeout        time.Duration `json:"msg_timeout"`        // 消息处理超时，超时未确认由 nsqd 重新投递，默认使用 nsqd 配置
+	Codec             string        `json:"codec"`              // 消息编码方式：json（默认）、proto
+	DisableMetric     bool          `json:"disable_metric"`     // 是否禁用监控，默认开启
+	DisableTrace      bool          `json:"disable_trace"`      // 是否禁用链路追踪，默认开启
+	DisableLogging    bool          `json:"disable_logging"`    // 是否禁用日志，默认开启
+
+	// EnableEnvelope 是否在生产的消息体前写入信封以携带链路信息，默认关闭，消息体与原始内容一致。
+	// 开启后消息体格式变为 0x00 'N' 'X' 'T' | headers 长度(uint32, 大端) | headers(JSON) | body，
+	// 本组件的消费者会自动解包，其他消费者需先升级为本组件或自行解包后再开启；
+	// 投递到死信主题的消息总是携带信封，以记录失败原因
+	EnableEnvelope bool `json:"enable_envelope"`
 }
 
 type Producer struct {
-	Addr string `json:"addr"` // nsqd 地址
+	Addr     string   `json:"addr"`     // nsqd 地址
+	Addrs    []string `json:"addrs"`    // 多个 nsqd 地址，与 addr 合并使用
+	Strategy string   `json:"strategy"` // 多个 nsqd 时的发布策略：failover（默认，按顺序故障转移）、round_robin（轮询）
 }
 
+// TLS 连接 nsqd 的 TLS 配置
+type TLS = tlsconfig.Config
+
 type Consumer struct {
 	Addr    string `json:"addr"`    // nsqlookupd 地址
 	Topic   string `json:"topic"`   // 主题
 	Channel string `json:"channel"` // 频道
+
+	// 重试策略，max_attempts 大于0时生效，处理失败的消息按指数退避重新入队，
+	// 达到最大投递次数后投递到死信主题，未配置死信主题时丢弃
+	MaxAttempts        uint16        `json:"max_attempts"`         // 最大投递次数，默认0即使用 go-nsq 的默认行为
+	RequeueDelay       time.Duration `json:"requeue_delay"`        // 重新入队的初始延迟，默认1s，每次翻倍
+	MaxRequeueDelay    time.Duration `json:"max_requeue_delay"`    // 重新入队的最大延迟，默认1m
+	DeadLetterTopic    string        `json:"dead_letter_topic"`    // 死信主题
+	DeadLetterProducer string        `json:"dead_letter_producer"` // 投递死信使用的生产者实例，默认与消费者同名
 }
 
 type nsqConfig struct{}
@@ -50,5 +95,243 @@ func WithConfig(cfg Options) loader.Option {
 
 // Options returns the nsq config.
 func (o *Options) Options() []Option {
-	return []Option{}
+	var opts []Option
+	// 地址选项会创建生产者、消费者配置，需先于仅对已配置角色生效的选项应用
+	if o.Producer != nil && len(o.Producer.addrs()) > 0 {
+		opts = append(opts, WithProducer(o.Producer.addrs()...))
+	}
+	if o.Consumer != nil && (o.Consumer.Addr != "" || o.Consumer.Topic != "" || o.Consumer.Channel != "") {
+		opts = append(opts, WithConsumer(o.Consumer.Addr, o.Consumer.Topic, o.Consumer.Channel))
+	}
+	if o.Producer != nil && o.Producer.Strategy != "" {
+		opts = append(opts, WithStrategy(o.Producer.Strategy))
+	}
+	if o.AuthSecret != "" {
+		opts = append(opts, WithAuthSecret(o.AuthSecret))
+	}
+	if o.TLS != nil {
+		opts = append(opts, WithTLS(*o.TLS))
+	}
+	if o.MaxInFlight > 0 {
+		opts = append(opts, WithMaxInFlight(o.MaxInFlight))
+	}
+	if o.HeartbeatInterval > 0 {
+		opts = append(opts, WithHeartbeatInterval(o.HeartbeatInterval))
+	}
+	if o.DialTimeout > 0 {
+		opts = append(opts, WithDialTimeout(o.DialTimeout))
+	}
+	if o.ReadTimeout > 0 {
+		opts = append(opts, WithReadTimeout(o.ReadTimeout))
+	}
+	if o.WriteTimeout > 0 {
+		opts = append(opts, WithWriteTimeout(o.WriteTimeout))
+	}
+	if o.MsgTimeout > 0 {
+		opts = append(opts, WithMsgTimeout(o.MsgTimeout))
+	}
+	if o.Codec != "" {
+		opts = append(opts, WithCodec(o.Codec))
+	}
+	if o.Consumer != nil && o.Consumer.MaxAttempts > 0 {
+		opts = append(opts, WithRetryPolicy(o.Consumer.MaxAttempts, o.Consumer.RequeueDelay, o.Consumer.MaxRequeueDelay))
+	}
+	if o.Consumer != nil && o.Consumer.DeadLetterTopic != "" {
+		opts = append(opts, WithDeadLetter(o.Consumer.DeadLetterTopic, o.Consumer.DeadLetterProducer))
+	}
+	if o.DisableMetric {
+		opts = append(opts, WithDisableMetric())
+	}
+	if o.DisableTrace {
+		opts = append(opts, WithDisableTrace())
+	}
+	if o.DisableLogging {
+		opts = append(opts, WithDisableLogging())
+	}
+	if o.EnableEnvelope {
+		opts = append(opts, WithEnableEnvelope())
+	}
+	return opts
+}
+
+// WithProducer 设置生产者连接的 nsqd 地址，多个地址时按发布策略选择，未配置生产者时创建
+func WithProducer(addrs ...string) Option {
+	return OptionFunc(func(cfg *Options) {
+		if cfg.Producer == nil {
+			cfg.Producer = &Producer{}
+		}
+		cfg.Producer.Addr = ""
+		cfg.Producer.Addrs = addrs
+	})
+}
+
+// WithConsumer 设置消费者的 nsqlookupd 地址、主题与频道，未配置消费者时创建
+func WithConsumer(addr, topic, channel string) Option {
+	return OptionFunc(func(cfg *Options) {
+		if cfg.Consumer == nil {
+			cfg.Consumer = &Consumer{}
+		}
+		cfg.Consumer.Addr = addr
+		cfg.Consumer.Topic = topic
+		cfg.Consumer.Channel = channel
+	})
+}
+
+// WithStrategy 设置多个 nsqd 时的发布策略，仅对配置了生产者的实例生效
+func WithStrategy(strategy string) Option {
+	return OptionFunc(func(cfg *Options) {
+		if cfg.Producer != nil {
+			cfg.Producer.Strategy = strategy
+		}
+	})
+}
+
+// WithAuthSecret 设置 nsqd 鉴权密钥
+func WithAuthSecret(secret string) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.AuthSecret = secret
+	})
+}
+
+// WithTLS 设置 TLS 配置
+func WithTLS(tls TLS) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.TLS = &tls
+	})
+}
+
+// WithMaxInFlight 设置消费者最大未确认消息数
+func WithMaxInFlight(n int) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.MaxInFlight = n
+	})
+}
+
+// WithHeartbeatInterval 设置心跳间隔
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.HeartbeatInterval = interval
+	})
+}
+
+// WithDialTimeout 设置拨号超时
+func WithDialTimeout(timeout time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.DialTimeout = timeout
+	})
+}
+
+// WithReadTimeout 设置读超时
+func WithReadTimeout(timeout time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.ReadTimeout = timeout
+	})
+}
+
+// WithWriteTimeout 设置写超时
+func WithWriteTimeout(timeout time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.WriteTimeout = timeout
+	})
+}
+
+// WithMsgTimeout 设置消息处理超时
+func WithMsgTimeout(timeout time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.MsgTimeout = timeout
+	})
+}
+
+// WithCodec 设置消息编码方式，name 为已注册的 kratos encoding 名称
+func WithCodec(name string) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Codec = name
+	})
+}
+
+// WithRetryPolicy 设置消费失败的重试策略，仅对配置了消费者的实例生效
+func WithRetryPolicy(maxAttempts uint16, requeueDelay, maxRequeueDelay time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		if cfg.Consumer != nil {
+			cfg.Consumer.MaxAttempts = maxAttempts
+			cfg.Consumer.RequeueDelay = requeueDelay
+			cfg.Consumer.MaxRequeueDelay = maxRequeueDelay
+		}
+	})
+}
+
+// WithDeadLetter 设置死信主题与投递死信的生产者实例，仅对配置了消费者的实例生效
+func WithDeadLetter(topic, producer string) Option {
+	return OptionFunc(func(cfg *Options) {
+		if cfg.Consumer != nil {
+			cfg.Consumer.DeadLetterTopic = topic
+			cfg.Consumer.DeadLetterProducer = producer
+		}
+	})
+}
+
+// WithDisableMetric 设置禁用监控
+func WithDisableMetric() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.DisableMetric = true
+	})
+}
+
+// WithDisableTrace 设置禁用链路
+func WithDisableTrace() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.DisableTrace = true
+	})
+}
+
+// WithDisableLogging 设置禁用日志
+func WithDisableLogging() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.DisableLogging = true
+	})
+}
+
+// WithEnableEnvelope 设置在消息体前写入信封以携带链路信息，会改变消息体格式
+func WithEnableEnvelope() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.EnableEnvelope = true
+	})
+}
+
+// addrs 返回生产者配置的全部 nsqd 地址，addr 在前并去重
+func (p *Producer) addrs() []string {
+	var ret []string
+	seen := make(map[string]struct{})
+	for _, addr := range append([]string{p.Addr}, p.Addrs...) {
+		if _, ok := seen[addr]; ok || addr == "" {
+			continue
+		}
+		seen[addr] = struct{}{}
+		ret = append(ret, addr)
+	}
+	return ret
+}
+
+// codec 返回消息编解码器，未注册时返回 nil
+func (o *Options) codec() encoding.Codec {
+	if o.Codec == "" {
+		return encoding.GetCodec(defaultCodec)
+	}
+	return encoding.GetCodec(o.Codec)
+}
+
+// requeueDelay 返回第 attempts 次投递失败后重新入队的延迟
+func (c *Consumer) requeueDelay(attempts uint16) time.Duration {
+	delay, maxDelay := c.RequeueDelay, c.MaxRequeueDelay
+	if delay <= 0 {
+		delay = defaultRequeueDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxRequeueDelay
+	}
+
+	for i := uint16(1); i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
 }
@@ -0,0 +1,56 @@
+package nsq
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOptions_Options(t *testing.T) {
+	src := Options{
+		Producer: &Producer{Addrs: []string{"127.0.0.1:4150", "127.0.0.1:4250"}, Strategy: StrategyRoundRobin},
+		Consumer: &Consumer{
+			Addr:               "127.0.0.1:4161",
+			Topic:              "orders",
+			Channel:            "billing",
+			MaxAttempts:        5,
+			RequeueDelay:       time.Second,
+			MaxRequeueDelay:    time.Minute,
+			DeadLetterTopic:    "orders.dlq",
+			DeadLetterProducer: "dlq",
+		},
+		AuthSecret:        "secret",
+		TLS:               &TLS{ServerName: "nsq"},
+		MaxInFlight:       10,
+		HeartbeatInterval: time.Second,
+		DialTimeout:       time.Second,
+		ReadTimeout:       time.Second,
+		WriteTimeout:      time.Second,
+		MsgTimeout:        time.Second,
+		Codec:             "proto",
+		DisableMetric:     true,
+		DisableTrace:      true,
+		DisableLogging:    true,
+		EnableEnvelope:    true,
+	}
+
+	var got Options
+	for _, o := range src.Options() {
+		o.apply(&got)
+	}
+	if !reflect.DeepEqual(got, src) {
+		t.Fatalf("Options round trip = %+v, want %+v", got, src)
+	}
+}
+
+func TestWithProducer_MergesAddr(t *testing.T) {
+	src := Options{Producer: &Producer{Addr: "a:4150", Addrs: []string{"b:4150", "a:4150"}}}
+
+	var got Options
+	for _, o := range src.Options() {
+		o.apply(&got)
+	}
+	if want := []string{"a:4150", "b:4150"}; !reflect.DeepEqual(got.Producer.Addrs, want) {
+		t.Fatalf("producer addrs = %v, want %v", got.Producer.Addrs, want)
+	}
+}
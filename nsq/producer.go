@@ -0,0 +1,398 @@
+package nsq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	prom "github.com/go-kratos/kratos/contrib/metrics/prometheus/v2"
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/nextmicro/gokit/timex"
+	"github.com/nextmicro/logger"
+	"github.com/nextmicro/next-component/internal/envelope"
+	"github.com/nextmicro/next/pkg/metrics"
+	nsq "github.com/nsqio/go-nsq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	kind       = "nsq"
+	tracerName = "github.com/nextmicro/next-component/nsq"
+)
+
+var (
+	tracer = otel.Tracer(tracerName)
+
+	producerRequests = prom.NewCounter(metrics.MessagingProducerMetricRequests)
+	producerSeconds  = prom.NewHistogram(metrics.MessagingProducerMetricMillisecond)
+)
+
+// Publisher 生产者，发布消息时按实例配置编码消息并记录链路、监控与日志，
+// 消息为 []byte 时原样发布，否则按实例配置的 codec 编码，组件停止后发布返回 ErrProducerClosed
+type Publisher struct {
+	name  string
+	opt   *Options
+	codec encoding.Codec
+	pool  *producerPool
+}
+
+// producerPool 同一实例下每个 nsqd 对应一个生产者
+type producerPool struct {
+	strategy  string
+	next      atomic.Uint32
+	mu        sync.Mutex
+	closed    bool           // 停止后不再接受发布
+	pending   sync.WaitGroup // 未完成的发布
+	producers []*nsq.Producer
+}
+
+// acquire 计入一次待完成的发布，停止后返回 ErrProducerClosed。
+// closed 与 pending.Add 在同一把锁下，保证 close 之后不会再与 Wait 并发调用 Add
+func (p *producerPool) acquire() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrProducerClosed
+	}
+	p.pending.Add(1)
+	return nil
+}
+
+// close 停止接受发布，之后可以安全地等待 pending
+func (p *producerPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+}
+
+// publish 按发布策略选择起始生产者，发布失败时依次尝试其余生产者
+func (p *producerPool) publish(fn func(producer *nsq.Producer) error) (err error) {
+	start := 0
+	if p.strategy == StrategyRoundRobin {
+		start = int((p.next.Add(1) - 1) % uint32(len(p.producers)))
+	}
+	for i := range p.producers {
+		if err = fn(p.producers[(start+i)%len(p.producers)]); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// GetPublisher 获取生产者，name 为空时返回 default 实例，实例不存在时返回 ErrInstanceNotFound
+func (c *Component) GetPublisher(name string) (*Publisher, error) {
+	if name == "" {
+		name = defaultName
+	}
+
+	value, ok := c.producer.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("nsq: producer %w, group: %s", ErrInstanceNotFound, name)
+	}
+
+	opt := c.opts[name]
+	return &Publisher{name: name, opt: opt, codec: opt.codec(), pool: value.(*producerPool)}, nil
+}
+
+// MustGetPublisher 获取生产者，实例不存在时 panic
+func (c *Component) MustGetPublisher(name string) *Publisher {
+	p, err := c.GetPublisher(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// Publisher 获取生产者，等同于 MustGetPublisher，未传 name 时返回 default 实例
+func (c *Component) Publisher(name ...string) *Publisher {
+	var group string
+	if len(name) > 0 {
+		group = name[0]
+	}
+
+	return c.MustGetPublisher(group)
+}
+
+// Publish 同步发布消息，未禁用链路时会将 ctx 中的链路信息写入消息体
+func (p *Publisher) Publish(ctx context.Context, topic string, v interface{}) error {
+	return p.do(ctx, topic, nil, []interface{}{v}, func(producer *nsq.Producer, bodies [][]byte) error {
+		return producer.Publish(topic, bodies[0])
+	})
+}
+
+// DeferredPublish 同步发布延迟消息，消息在 delay 之后才会投递给消费者
+func (p *Publisher) DeferredPublish(ctx context.Context, topic string, delay time.Duration, v interface{}) error {
+	return p.do(ctx, topic, nil, []interface{}{v}, func(producer *nsq.Producer, bodies [][]byte) error {
+		return producer.DeferredPublish(topic, delay, bodies[0])
+	})
+}
+
+// MultiPublish 同步批量发布消息，同一批消息发往同一个 nsqd
+func (p *Publisher) MultiPublish(ctx context.Context, topic string, vs ...interface{}) error {
+	if len(vs) == 0 {
+		return nil
+	}
+
+	return p.do(ctx, topic, nil, vs, func(producer *nsq.Producer, bodies [][]byte) error {
+		return producer.MultiPublish(topic, bodies)
+	})
+}
+
+// PublishAsync 异步发布消息，发布完成后调用 callback，callback 可为空
+func (p *Publisher) PublishAsync(ctx context.Context, topic string, v interface{}, callback func(err error)) {
+	p.doAsync(ctx, topic, []interface{}{v}, func(producer *nsq.Producer, bodies [][]byte, done chan *nsq.ProducerTransaction) error {
+		return producer.PublishAsync(topic, bodies[0], done)
+	}, callback)
+}
+
+// DeferredPublishAsync 异步发布延迟消息，发布完成后调用 callback，callback 可为空
+func (p *Publisher) DeferredPublishAsync(ctx context.Context, topic string, delay time.Duration, v interface{}, callback func(err error)) {
+	p.doAsync(ctx, topic, []interface{}{v}, func(producer *nsq.Producer, bodies [][]byte, done chan *nsq.ProducerTransaction) error {
+		return producer.DeferredPublishAsync(topic, delay, bodies[0], done)
+	}, callback)
+}
+
+// MultiPublishAsync 异步批量发布消息，发布完成后调用 callback，callback 可为空
+func (p *Publisher) MultiPublishAsync(ctx context.Context, topic string, vs []interface{}, callback func(err error)) {
+	if len(vs) == 0 {
+		if callback != nil {
+			callback(nil)
+		}
+		return
+	}
+
+	p.doAsync(ctx, topic, vs, func(producer *nsq.Producer, bodies [][]byte, done chan *nsq.ProducerTransaction) error {
+		return producer.MultiPublishAsync(topic, bodies, done)
+	}, callback)
+}
+
+// publishWithHeader 同步发布携带自定义 header 的消息，header 与链路信息一同写入消息信封
+func (p *Publisher) publishWithHeader(ctx context.Context, topic string, header map[string]string, body []byte) error {
+	return p.do(ctx, topic, header, []interface{}{body}, func(producer *nsq.Producer, bodies [][]byte) error {
+		return producer.Publish(topic, bodies[0])
+	})
+}
+
+func (p *Publisher) do(ctx context.Context, topic string, header map[string]string, vs []interface{}, fn func(producer *nsq.Producer, bodies [][]byte) error) error {
+	if err := p.pool.acquire(); err != nil {
+		return err
+	}
+	defer p.pool.pending.Done()
+
+	bodies, finish, err := p.begin(ctx, topic, header, vs)
+	if err != nil {
+		return err
+	}
+
+	var addr string
+	err = p.pool.publish(func(producer *nsq.Producer) error {
+		addr = producer.String()
+		return fn(producer, bodies)
+	})
+	finish(addr, err)
+	return err
+}
+
+func (p *Publisher) doAsync(ctx context.Context, topic string, vs []interface{}, fn func(producer *nsq.Producer, bodies [][]byte, done chan *nsq.ProducerTransaction) error, callback func(err error)) {
+	if callback == nil {
+		callback = func(error) {}
+	}
+
+	// 发布前计入待完成事务，保证 Stop 等待期间发出的事务不会被遗漏
+	if err := p.pool.acquire(); err != nil {
+		callback(err)
+		return
+	}
+
+	bodies, finish, err := p.begin(ctx, topic, nil, vs)
+	if err != nil {
+		p.pool.pending.Done()
+		callback(err)
+		return
+	}
+
+	var addr string
+	done := make(chan *nsq.ProducerTransaction, 1)
+	err = p.pool.publish(func(producer *nsq.Producer) error {
+		addr = producer.String()
+		return fn(producer, bodies, done)
+	})
+	if err != nil {
+		p.pool.pending.Done()
+		finish(addr, err)
+		callback(err)
+		return
+	}
+
+	go func() {
+		defer p.pool.pending.Done()
+		t := <-done
+		finish(addr, t.Error)
+		callback(t.Error)
+	}()
+}
+
+// begin 编码消息并开启链路，header 与开启信封时的链路信息写入每条消息的信封，返回的 finish 在发布完成后记录链路、监控与日志；
+// 编码失败时已调用过 finish
+func (p *Publisher) begin(ctx context.Context, topic string, header map[string]string, vs []interface{}) ([][]byte, func(addr string, err error), error) {
+	start := time.Now()
+	span := trace.SpanFromContext(ctx)
+	if !p.opt.DisableTrace {
+		ctx, span = tracer.Start(ctx, topic+" publish",
+			trace.WithSpanKind(trace.SpanKindProducer),
+			trace.WithAttributes(
+				semconv.MessagingSystemKey.String(kind),
+				semconv.MessagingDestinationName(topic),
+				semconv.MessagingOperationPublish,
+				semconv.MessagingBatchMessageCount(len(vs)),
+			),
+		)
+	}
+
+	finish := func(addr string, err error) {
+		duration := time.Since(start)
+		if !p.opt.DisableTrace {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}
+
+		if !p.opt.DisableMetric {
+			code := codes.Ok
+			if err != nil {
+				code = codes.Error
+			}
+			producerRequests.With(kind, addr, topic, code.String()).Inc()
+			producerSeconds.With(kind, addr, topic).Observe(float64(duration.Milliseconds()))
+		}
+
+		if !p.opt.DisableLogging {
+			fields := map[string]interface{}{
+				"kind":      "mq",
+				"component": kind,
+				"name":      p.name,
+				"addr":      addr,
+				"topic":     topic,
+				"count":     len(vs),
+				"duration":  timex.Duration(duration),
+			}
+			log := logger.WithContext(ctx)
+			if err != nil {
+				fields["error"] = err
+				log.WithFields(fields).Error("nsq producer")
+			} else {
+				log.WithFields(fields).Info("nsq producer")
+			}
+		}
+	}
+
+	headers := propagation.MapCarrier{}
+	for k, v := range header {
+		headers[k] = v
+	}
+	// 链路信息仅在开启信封时写入，避免改变未升级的消费者读取到的消息体
+	if !p.opt.DisableTrace && p.opt.EnableEnvelope {
+		otel.GetTextMapPropagator().Inject(ctx, headers)
+	}
+
+	bodies := make([][]byte, 0, len(vs))
+	for _, v := range vs {
+		body, err := p.marshal(v)
+		if err == nil {
+			body, err = envelope.Encode(headers, body)
+		}
+		if err != nil {
+			err = fmt.Errorf("nsq: encode message %w", err)
+			finish("", err)
+			return nil, nil, err
+		}
+		bodies = append(bodies, body)
+	}
+
+	return bodies, finish, nil
+}
+
+func (p *Publisher) marshal(v interface{}) ([]byte, error) {
+	if body, ok := v.([]byte); ok {
+		return body, nil
+	}
+
+	return p.codec.Marshal(v)
+}
+
+// newProducerPool 为每个 nsqd 地址创建生产者，至少一个 nsqd 可用即视为成功
+func newProducerPool(opt *Producer, cfg *nsq.Config) (*producerPool, error) {
+	addrs := opt.addrs()
+	if len(addrs) == 0 {
+		return nil, errors.New("no nsqd address")
+	}
+
+	switch opt.Strategy {
+	case "", StrategyFailover, StrategyRoundRobin:
+	default:
+		return nil, fmt.Errorf("unknown strategy %q", opt.Strategy)
+	}
+
+	pool := &producerPool{strategy: opt.Strategy}
+	var errs []error
+	for _, addr := range addrs {
+		p, err := nsq.NewProducer(addr, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("NewProducer %w", err)
+		}
+		if err = p.Ping(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", addr, err))
+			logger.Warnf("nsq: producer ping %s error: %v", addr, err)
+		}
+		pool.producers = append(pool.producers, p)
+	}
+	if len(errs) == len(addrs) {
+		for _, p := range pool.producers {
+			p.Stop()
+		}
+		return nil, fmt.Errorf("Ping error %w", errors.Join(errs...))
+	}
+
+	return pool, nil
+}
+
+// stopProducers 停止接受发布，等待已接受的发布完成后停止生产者，ctx 结束时不再等待，
+// 未完成的异步发布以 nsq.ErrStopped 回调，之后的发布返回 ErrProducerClosed
+func (c *Component) stopProducers(ctx context.Context) error {
+	var pools []*producerPool
+	c.producer.Range(func(key, value interface{}) bool {
+		pools = append(pools, value.(*producerPool))
+		return true
+	})
+
+	for _, pool := range pools {
+		pool.close()
+	}
+	err := wait(ctx, func() {
+		for _, pool := range pools {
+			pool.pending.Wait()
+		}
+	})
+	for _, pool := range pools {
+		for _, p := range pool.producers {
+			p.Stop()
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("nsq: flush async publishes %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package nsq
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextmicro/next-component/internal/envelope"
+	nsq "github.com/nsqio/go-nsq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestProducerPool_Publish(t *testing.T) {
+	newPool := func(strategy string) *producerPool {
+		pool := &producerPool{strategy: strategy}
+		for _, addr := range []string{"127.0.0.1:4150", "127.0.0.1:4250", "127.0.0.1:4350"} {
+			p, err := nsq.NewProducer(addr, nsq.NewConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			pool.producers = append(pool.producers, p)
+		}
+		return pool
+	}
+	publish := func(pool *producerPool, down string) (string, []string) {
+		var tried []string
+		var addr string
+		err := pool.publish(func(p *nsq.Producer) error {
+			tried = append(tried, p.String())
+			if p.String() == down {
+				return errors.New("down")
+			}
+			addr = p.String()
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return addr, tried
+	}
+
+	failover := newPool(StrategyFailover)
+	for i := 0; i < 2; i++ {
+		if addr, _ := publish(failover, ""); addr != "127.0.0.1:4150" {
+			t.Fatalf("failover published to %s", addr)
+		}
+	}
+	if addr, tried := publish(failover, "127.0.0.1:4150"); addr != "127.0.0.1:4250" || len(tried) != 2 {
+		t.Fatalf("failover published to %s, tried %v", addr, tried)
+	}
+
+	roundRobin := newPool(StrategyRoundRobin)
+	var addrs []string
+	for i := 0; i < 4; i++ {
+		addr, _ := publish(roundRobin, "127.0.0.1:4250")
+		addrs = append(addrs, addr)
+	}
+	want := []string{"127.0.0.1:4150", "127.0.0.1:4350", "127.0.0.1:4350", "127.0.0.1:4150"}
+	if !reflect.DeepEqual(addrs, want) {
+		t.Fatalf("round robin published to %v, want %v", addrs, want)
+	}
+}
+
+func TestPublisher_Encode(t *testing.T) {
+	opt := &Options{DisableMetric: true, DisableLogging: true}
+	p := &Publisher{name: defaultName, opt: opt, codec: opt.codec()}
+
+	type payload struct {
+		ID int `json:"id"`
+	}
+	bodies, finish, err := p.begin(context.Background(), "test", nil, []interface{}{payload{ID: 1}, []byte("raw")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	finish("127.0.0.1:4150", nil)
+
+	_, body := envelope.Decode(bodies[0])
+	var got payload
+	msg := &Message{Message: nsq.NewMessage(nsq.MessageID{}, body), codec: opt.codec()}
+	if err = msg.Decode(&got); err != nil || got.ID != 1 {
+		t.Fatalf("decode = %+v, %v", got, err)
+	}
+	if _, body = envelope.Decode(bodies[1]); string(body) != "raw" {
+		t.Fatalf("raw body = %q", body)
+	}
+
+	if _, _, err = p.begin(context.Background(), "test", nil, []interface{}{make(chan int)}); err == nil {
+		t.Fatal("expected encode error")
+	}
+}
+
+func TestComponent_StopTimeout(t *testing.T) {
+	p, err := nsq.NewProducer("127.0.0.1:4150", nsq.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := &producerPool{producers: []*nsq.Producer{p}}
+	pool.pending.Add(1)
+	defer pool.pending.Done()
+
+	c := New()
+	c.producer.Store(defaultName, pool)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err = c.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestComponent_StopDuringAsyncPublish(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+
+	p, err := nsq.NewProducer(addr, nsq.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetLogger(nil, nsq.LogLevelError)
+	pool := &producerPool{producers: []*nsq.Producer{p}}
+	opt := &Options{DisableMetric: true, DisableTrace: true, DisableLogging: true}
+	pub := &Publisher{name: defaultName, opt: opt, codec: opt.codec(), pool: pool}
+
+	c := New()
+	c.producer.Store(defaultName, pool)
+
+	// 模拟一个尚未完成的异步发布
+	if err = pool.acquire(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stop 期间持续并发发布，Add 与 Wait 不会并发执行
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				pub.PublishAsync(context.Background(), "test", []byte("body"), nil)
+			}
+		}()
+	}
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- c.Stop(context.Background()) }()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		pool.mu.Lock()
+		closed := pool.closed
+		pool.mu.Unlock()
+		if closed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("pool not closed by Stop")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// 停止后的发布立即返回 ErrProducerClosed
+	errc := make(chan error, 1)
+	pub.PublishAsync(context.Background(), "test", []byte("body"), func(err error) { errc <- err })
+	if err = <-errc; !errors.Is(err, ErrProducerClosed) {
+		t.Fatalf("PublishAsync() after Stop error = %v, want %v", err, ErrProducerClosed)
+	}
+	if err = pub.Publish(context.Background(), "test", []byte("body")); !errors.Is(err, ErrProducerClosed) {
+		t.Fatalf("Publish() after Stop error = %v, want %v", err, ErrProducerClosed)
+	}
+
+	// Stop 等待已接受的发布完成
+	select {
+	case err = <-stopped:
+		t.Fatalf("Stop() returned %v before the pending publish completed", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	pool.pending.Done()
+	if err = <-stopped; err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	cancel()
+	wg.Wait()
+}
+
+// fixedPropagator 总是写入固定的 header，用于验证链路信息是否写入信封
+type fixedPropagator struct{}
+
+func (fixedPropagator) Inject(_ context.Context, carrier propagation.TextMapCarrier) {
+	carrier.Set("traceparent", "fixed")
+}
+
+func (fixedPropagator) Extract(ctx context.Context, _ propagation.TextMapCarrier) context.Context {
+	return ctx
+}
+
+func (fixedPropagator) Fields() []string { return []string{"traceparent"} }
+
+func TestPublisher_EnableEnvelope(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(fixedPropagator{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	tests := []struct {
+		name   string
+		opt    Options
+		header map[string]string
+		want   map[string]string
+	}{
+		{name: "disabled by default"},
+		{name: "enabled", opt: Options{EnableEnvelope: true}, want: map[string]string{"traceparent": "fixed"}},
+		{name: "enabled without trace", opt: Options{EnableEnvelope: true, DisableTrace: true}},
+		{
+			name:   "explicit header",
+			header: map[string]string{HeaderDeadLetterTopic: "test"},
+			want:   map[string]string{HeaderDeadLetterTopic: "test"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := tt.opt
+			opt.DisableMetric, opt.DisableLogging = true, true
+			p := &Publisher{name: defaultName, opt: &opt, codec: opt.codec()}
+
+			bodies, finish, err := p.begin(context.Background(), "test", tt.header, []interface{}{[]byte("raw")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			finish("127.0.0.1:4150", nil)
+
+			if tt.want == nil {
+				if string(bodies[0]) != "raw" {
+					t.Fatalf("body = %q, want the raw body without envelope", bodies[0])
+				}
+				return
+			}
+			headers, body := envelope.Decode(bodies[0])
+			if !reflect.DeepEqual(headers, tt.want) || string(body) != "raw" {
+				t.Fatalf("Decode() = %v, %q, want %v, raw", headers, body, tt.want)
+			}
+		})
+	}
+}
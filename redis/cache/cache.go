@@ -0,0 +1,322 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	prom "github.com/go-kratos/kratos/contrib/metrics/prometheus/v2"
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/nextmicro/logger"
+	"github.com/nextmicro/next/pkg/metrics"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	component       = "redis"
+	kindLocal       = "local"
+	defaultLocalTTL = time.Minute
+
+	defaultLoadTimeout = 10 * time.Second
+
+	// 监控的 command 标签，读取的 status 为 hit、miss、error，
+	// 本地淘汰的 status 为淘汰原因 capacity、expired、invalidated
+	commandGet   = "cache.get"
+	commandEvict = "cache.evict"
+)
+
+var (
+	// ErrNotFound loader 返回该错误表示数据不存在，开启空值缓存时会被缓存
+	ErrNotFound = errors.New("cache: not found")
+
+	errInvalidValue = errors.New("cache: invalid value")
+)
+
+// 缓存值的首字节标记，区分正常值与空值
+const (
+	flagNotFound byte = iota
+	flagValue
+)
+
+// invalidation 失效通知消息
+type invalidation struct {
+	ID   string   `json:"id"`   // 发送方 Cache 标识，忽略自己发出的通知
+	Keys []string `json:"keys"` // 失效的 key
+}
+
+// Loader 缓存未命中时从数据源加载数据，数据不存在时返回 ErrNotFound
+type Loader func(ctx context.Context) (interface{}, error)
+
+// Cache 基于 redis 的 cache-aside 缓存，同一 key 并发未命中时只调用一次 loader，
+// 可选在 redis 前增加进程内缓存
+type Cache struct {
+	client redis.UniversalClient
+	opt    *options
+	group  singleflight.Group
+
+	id    string
+	local *local
+	sub   *redis.PubSub
+	wg    sync.WaitGroup
+}
+
+// New 创建缓存，client 可以是任意 redis.UniversalClient。
+// Cache 需长期持有复用，才能合并同一 key 的并发加载
+func New(client redis.UniversalClient, opts ...Option) *Cache {
+	opt := &options{
+		codec:       encoding.GetCodec("json"),
+		loadTimeout: defaultLoadTimeout,
+		requests:    prom.NewCounter(metrics.DBSystemMetricRequests),
+	}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	c := &Cache{client: client, opt: opt}
+	if opt.localSize > 0 {
+		if opt.localTTL <= 0 {
+			opt.localTTL = defaultLocalTTL
+		}
+		c.local = newLocal(opt.localSize, opt.localTTL, c.evicted)
+		if opt.channel != "" {
+			c.id = newID()
+			c.subscribe()
+		}
+	}
+	return c
+}
+
+// Close 停止接收失效通知，未开启失效通知时无需调用
+func (c *Cache) Close() error {
+	if c.sub == nil {
+		return nil
+	}
+
+	err := c.sub.Close()
+	c.wg.Wait()
+	return err
+}
+
+// Fetch 读取缓存并解码到 v，未命中时调用 loader 加载数据并以 ttl 写入缓存，
+// 数据不存在（含命中空值缓存）时返回 ErrNotFound。redis 读取失败时降级为直接调用 loader
+func (c *Cache) Fetch(ctx context.Context, key string, ttl time.Duration, v interface{}, loader Loader) error {
+	if c.local != nil {
+		if data, ok := c.local.get(key); ok {
+			c.observe(kindLocal, "hit")
+			return c.decode(data, v)
+		}
+		c.observe(kindLocal, "miss")
+	}
+
+	data, remaining, err := c.get(ctx, key)
+	switch {
+	case err == nil:
+		c.observe(component, "hit")
+		c.setLocal(key, data, remaining)
+		return c.decode(data, v)
+	case errors.Is(err, redis.Nil):
+		c.observe(component, "miss")
+	default:
+		c.observe(component, "error")
+		logger.WithContext(ctx).WithFields(map[string]interface{}{
+			"kind":      "db",
+			"component": component,
+			"name":      c.opt.name,
+			"key":       key,
+			"error":     err,
+		}).Error("[REDIS] Cache Get")
+	}
+
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		// 共享的加载不受单个调用方取消影响，由 loadTimeout 控制时长
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.opt.loadTimeout)
+		defer cancel()
+		return c.load(ctx, key, ttl, loader)
+	})
+
+	// 调用方取消时立即返回，加载继续为其他等待方完成
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case ret := <-ch:
+		if ret.Err != nil {
+			return ret.Err
+		}
+		return c.decode(ret.Val.([]byte), v)
+	}
+}
+
+// Set 编码 v 并以 ttl 写入缓存，开启失效通知时通知其他实例淘汰本地缓存
+func (c *Cache) Set(ctx context.Context, key string, v interface{}, ttl time.Duration) error {
+	data, err := c.encode(v)
+	if err != nil {
+		return err
+	}
+
+	if err = c.client.Set(ctx, key, data, ttl).Err(); err != nil {
+		return err
+	}
+	c.setLocal(key, data, ttl)
+	return c.invalidate(ctx, key)
+}
+
+// Delete 删除缓存，数据源更新后调用，开启失效通知时通知其他实例淘汰本地缓存
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if c.local != nil {
+		c.local.delete(keys...)
+	}
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		return err
+	}
+	return c.invalidate(ctx, keys...)
+}
+
+// load 调用 loader 并写入缓存，写入失败只记录日志
+func (c *Cache) load(ctx context.Context, key string, ttl time.Duration, loader Loader) ([]byte, error) {
+	value, err := loader(ctx)
+
+	var data []byte
+	switch {
+	case errors.Is(err, ErrNotFound):
+		if c.opt.negativeTTL <= 0 {
+			return nil, err
+		}
+		data, ttl = []byte{flagNotFound}, c.opt.negativeTTL
+	case err != nil:
+		return nil, err
+	default:
+		if data, err = c.encode(value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = c.client.Set(ctx, key, data, ttl).Err(); err != nil {
+		logger.WithContext(ctx).WithFields(map[string]interface{}{
+			"kind":      "db",
+			"component": component,
+			"name":      c.opt.name,
+			"key":       key,
+			"error":     err,
+		}).Error("[REDIS] Cache Set")
+	}
+	c.setLocal(key, data, ttl)
+	return data, nil
+}
+
+// get 读取 redis，开启进程内缓存时同时读取剩余过期时间，使本地缓存不晚于 redis 过期
+func (c *Cache) get(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	if c.local == nil {
+		data, err := c.client.Get(ctx, key).Bytes()
+		return data, 0, err
+	}
+
+	var (
+		get *redis.StringCmd
+		ttl *redis.DurationCmd
+	)
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		ttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, err
+	}
+
+	data, err := get.Bytes()
+	return data, ttl.Val(), err
+}
+
+func (c *Cache) setLocal(key string, data []byte, ttl time.Duration) {
+	if c.local != nil {
+		c.local.set(key, data, ttl)
+	}
+}
+
+// invalidate 广播失效通知
+func (c *Cache) invalidate(ctx context.Context, keys ...string) error {
+	if c.sub == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(invalidation{ID: c.id, Keys: keys})
+	if err != nil {
+		return err
+	}
+	return c.client.Publish(ctx, c.opt.channel, payload).Err()
+}
+
+// subscribe 订阅失效通知并淘汰本地缓存，通知丢失时（如订阅重连期间）本地缓存最多保留 localTTL
+func (c *Cache) subscribe() {
+	c.sub = c.client.Subscribe(context.Background(), c.opt.channel)
+	ch := c.sub.Channel()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for msg := range ch {
+			var inv invalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil || inv.ID == c.id {
+				continue
+			}
+			if n := c.local.delete(inv.Keys...); n > 0 {
+				c.evicted(evictInvalidated, n)
+			}
+		}
+	}()
+}
+
+func (c *Cache) encode(v interface{}) ([]byte, error) {
+	b, err := c.opt.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{flagValue}, b...), nil
+}
+
+func (c *Cache) decode(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return errInvalidValue
+	}
+
+	switch data[0] {
+	case flagNotFound:
+		return ErrNotFound
+	case flagValue:
+		return c.opt.codec.Unmarshal(data[1:], v)
+	default:
+		return errInvalidValue
+	}
+}
+
+func (c *Cache) observe(kind, status string) {
+	if c.opt.disableMetric {
+		return
+	}
+
+	c.opt.requests.With(kind, c.opt.name, c.opt.addr, commandGet, status).Inc()
+}
+
+func (c *Cache) evicted(reason string, n int) {
+	if c.opt.disableMetric {
+		return
+	}
+
+	c.opt.requests.With(kindLocal, c.opt.name, c.opt.addr, commandEvict, reason).Add(float64(n))
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
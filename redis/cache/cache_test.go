@@ -0,0 +1,245 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestCache(t *testing.T, opts ...Option) (*Cache, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	c := New(client, append([]Option{WithDisableMetric(true)}, opts...)...)
+	t.Cleanup(func() { _ = c.Close() })
+	return c, mr
+}
+
+func TestCache_FetchSharedLoadIgnoresCallerCancel(t *testing.T) {
+	c, _ := newTestCache(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	var loads int32
+	loader := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		once.Do(func() { close(started) })
+		<-release
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return "value", nil
+	}
+
+	first, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		var v string
+		firstErr <- c.Fetch(first, "key", time.Minute, &v, loader)
+	}()
+	<-started
+
+	var wg sync.WaitGroup
+	values := make([]string, 2)
+	errs := make([]error, 2)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.Fetch(context.Background(), "key", time.Minute, &values[i], loader)
+		}(i)
+	}
+
+	// 第一个调用方取消后立即返回，不等待共享的加载
+	cancel()
+	select {
+	case err := <-firstErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("cancelled Fetch() error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelled Fetch() blocked on the shared load")
+	}
+
+	// 共享的加载仍然完成并返回给其他调用方
+	close(release)
+	wg.Wait()
+	for i := range values {
+		if errs[i] != nil || values[i] != "value" {
+			t.Fatalf("Fetch() = %q, %v, want value", values[i], errs[i])
+		}
+	}
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Fatalf("loads = %d, want 1", n)
+	}
+}
+
+func TestCache_FetchLoadTimeout(t *testing.T) {
+	c, _ := newTestCache(t, WithLoadTimeout(20*time.Millisecond))
+
+	var v string
+	err := c.Fetch(context.Background(), "key", time.Minute, &v, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Fetch() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestCache_FetchLocalTier(t *testing.T) {
+	c, mr := newTestCache(t, WithLocal(10, time.Minute))
+	ctx := context.Background()
+
+	var loads int
+	loader := func(context.Context) (interface{}, error) {
+		loads++
+		return "value", nil
+	}
+
+	var v string
+	if err := c.Fetch(ctx, "key", time.Minute, &v, loader); err != nil || v != "value" {
+		t.Fatalf("Fetch() = %q, %v, want value", v, err)
+	}
+	if !mr.Exists("key") {
+		t.Fatal("value not written to redis")
+	}
+
+	// 本地命中时不再读取 redis
+	mr.Del("key")
+	v = ""
+	if err := c.Fetch(ctx, "key", time.Minute, &v, loader); err != nil || v != "value" {
+		t.Fatalf("Fetch() local = %q, %v, want value", v, err)
+	}
+	if loads != 1 {
+		t.Fatalf("loads = %d, want 1", loads)
+	}
+
+	// Delete 同时淘汰本地缓存
+	if err := c.Delete(ctx, "key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := c.Fetch(ctx, "key", time.Minute, &v, loader); err != nil || loads != 2 {
+		t.Fatalf("Fetch() after Delete loads = %d, %v, want 2", loads, err)
+	}
+}
+
+func TestCache_FetchLocalTierEntryTTL(t *testing.T) {
+	c, mr := newTestCache(t, WithLocal(10, time.Minute))
+	ctx := context.Background()
+
+	var loads int
+	loader := func(context.Context) (interface{}, error) {
+		loads++
+		return "value", nil
+	}
+
+	// loader 写入的值本地保留不超过 ttl
+	var v string
+	if err := c.Fetch(ctx, "loaded", 20*time.Millisecond, &v, loader); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	// redis 命中的值本地保留不超过剩余过期时间
+	if err := c.Set(ctx, "hit", "value", 20*time.Millisecond); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	c.local.delete("hit")
+	if err := c.Fetch(ctx, "hit", time.Minute, &v, loader); err != nil || loads != 1 {
+		t.Fatalf("Fetch() redis hit loads = %d, %v, want 1", loads, err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	mr.FastForward(30 * time.Millisecond)
+	for i, key := range []string{"loaded", "hit"} {
+		if err := c.Fetch(ctx, key, time.Minute, &v, loader); err != nil || loads != i+2 {
+			t.Fatalf("Fetch(%s) after ttl loads = %d, %v, want %d", key, loads, err, i+2)
+		}
+	}
+}
+
+func TestCache_FetchNegativeTTL(t *testing.T) {
+	c, mr := newTestCache(t, WithNegativeTTL(time.Second))
+	ctx := context.Background()
+
+	var loads int
+	loader := func(context.Context) (interface{}, error) {
+		loads++
+		return nil, ErrNotFound
+	}
+
+	var v string
+	for i := 0; i < 2; i++ {
+		if err := c.Fetch(ctx, "key", time.Minute, &v, loader); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Fetch() error = %v, want %v", err, ErrNotFound)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("loads = %d, want 1", loads)
+	}
+	if got := mr.TTL("key"); got != time.Second {
+		t.Fatalf("negative ttl = %v, want %v", got, time.Second)
+	}
+}
+
+func TestCache_Invalidation(t *testing.T) {
+	mr := miniredis.RunT(t)
+	newCache := func() *Cache {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+		c := New(client, WithDisableMetric(true), WithLocal(10, time.Minute), WithInvalidation("invalidation"))
+		t.Cleanup(func() { _ = c.Close() })
+		return c
+	}
+	a, b := newCache(), newCache()
+	ctx := context.Background()
+
+	// 等待两个实例都订阅成功
+	deadline := time.Now().Add(time.Second)
+	for mr.PubSubNumSub("invalidation")["invalidation"] < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("subscriptions not ready")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := a.Set(ctx, "key", "v1", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	var v string
+	if err := b.Fetch(ctx, "key", time.Minute, &v, nil); err != nil || v != "v1" {
+		t.Fatalf("Fetch() = %q, %v, want v1", v, err)
+	}
+
+	// a 更新后 b 的本地缓存被淘汰，读取到新值
+	if err := a.Set(ctx, "key", "v2", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	deadline = time.Now().Add(time.Second)
+	for {
+		if err := b.Fetch(ctx, "key", time.Minute, &v, nil); err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if v == "v2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Fetch() = %q after invalidation, want v2", v)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// 自己发出的通知不淘汰自己的本地缓存
+	if _, ok := a.local.get("key"); !ok {
+		t.Fatal("local cache of the publisher was evicted")
+	}
+}
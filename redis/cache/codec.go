@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"github.com/go-kratos/kratos/v2/encoding"
+	_ "github.com/go-kratos/kratos/v2/encoding/json"
+	_ "github.com/go-kratos/kratos/v2/encoding/proto"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MsgpackName is the name registered for the msgpack codec.
+const MsgpackName = "msgpack"
+
+func init() {
+	encoding.RegisterCodec(msgpackCodec{})
+}
+
+// msgpackCodec is a Codec implementation with msgpack.
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+func (msgpackCodec) Name() string {
+	return MsgpackName
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// 本地缓存淘汰原因
+const (
+	evictCapacity    = "capacity"
+	evictExpired     = "expired"
+	evictInvalidated = "invalidated"
+)
+
+// local 进程内 LRU 缓存，超过容量时淘汰最久未使用的 key，过期的 key 在读取时淘汰
+type local struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	ll      *list.List
+	items   map[string]*list.Element
+	onEvict func(reason string, n int)
+}
+
+type entry struct {
+	key      string
+	data     []byte
+	expireAt time.Time
+}
+
+func newLocal(size int, ttl time.Duration, onEvict func(reason string, n int)) *local {
+	return &local{
+		size:    size,
+		ttl:     ttl,
+		ll:      list.New(),
+		items:   make(map[string]*list.Element),
+		onEvict: onEvict,
+	}
+}
+
+func (l *local) get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	ent := e.Value.(*entry)
+	if time.Now().After(ent.expireAt) {
+		l.remove(e)
+		l.onEvict(evictExpired, 1)
+		return nil, false
+	}
+
+	l.ll.MoveToFront(e)
+	return ent.data, true
+}
+
+// set 写入 key，本地保留时长取 ttl 与 localTTL 的较小值，ttl <= 0 表示 redis 中不过期
+func (l *local) set(key string, data []byte, ttl time.Duration) {
+	if ttl <= 0 || ttl > l.ttl {
+		ttl = l.ttl
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expireAt := time.Now().Add(ttl)
+	if e, ok := l.items[key]; ok {
+		ent := e.Value.(*entry)
+		ent.data, ent.expireAt = data, expireAt
+		l.ll.MoveToFront(e)
+		return
+	}
+
+	l.items[key] = l.ll.PushFront(&entry{key: key, data: data, expireAt: expireAt})
+	if l.ll.Len() > l.size {
+		l.remove(l.ll.Back())
+		l.onEvict(evictCapacity, 1)
+	}
+}
+
+// delete 删除 key，返回实际删除的个数
+func (l *local) delete(keys ...string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var n int
+	for _, key := range keys {
+		if e, ok := l.items[key]; ok {
+			l.remove(e)
+			n++
+		}
+	}
+	return n
+}
+
+func (l *local) remove(e *list.Element) {
+	l.ll.Remove(e)
+	delete(l.items, e.Value.(*entry).key)
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+type evictions map[string]int
+
+func (e evictions) add(reason string, n int) { e[reason] += n }
+
+func TestLocal_CapacityEvictsLeastRecentlyUsed(t *testing.T) {
+	ev := evictions{}
+	l := newLocal(2, time.Minute, ev.add)
+
+	l.set("a", []byte("1"), 0)
+	l.set("b", []byte("2"), 0)
+	// 读取 a 后 b 成为最久未使用
+	if _, ok := l.get("a"); !ok {
+		t.Fatal("get(a) miss")
+	}
+	l.set("c", []byte("3"), 0)
+
+	if _, ok := l.get("b"); ok {
+		t.Fatal("get(b) hit, want evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := l.get(key); !ok {
+			t.Fatalf("get(%s) miss", key)
+		}
+	}
+	if ev[evictCapacity] != 1 {
+		t.Fatalf("capacity evictions = %d, want 1", ev[evictCapacity])
+	}
+}
+
+func TestLocal_SetExistingKeyKeepsSize(t *testing.T) {
+	ev := evictions{}
+	l := newLocal(2, time.Minute, ev.add)
+
+	l.set("a", []byte("1"), 0)
+	l.set("b", []byte("2"), 0)
+	l.set("a", []byte("3"), 0)
+
+	if data, ok := l.get("a"); !ok || string(data) != "3" {
+		t.Fatalf("get(a) = %q, %v, want 3", data, ok)
+	}
+	if _, ok := l.get("b"); !ok {
+		t.Fatal("get(b) miss")
+	}
+	if l.ll.Len() != 2 || len(ev) != 0 {
+		t.Fatalf("len = %d, evictions = %v, want 2 and none", l.ll.Len(), ev)
+	}
+}
+
+func TestLocal_TTL(t *testing.T) {
+	ev := evictions{}
+	l := newLocal(10, 20*time.Millisecond, ev.add)
+
+	l.set("a", []byte("1"), 0)
+	if _, ok := l.get("a"); !ok {
+		t.Fatal("get(a) miss before ttl")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := l.get("a"); ok {
+		t.Fatal("get(a) hit after ttl")
+	}
+	if ev[evictExpired] != 1 || l.ll.Len() != 0 {
+		t.Fatalf("expired evictions = %d, len = %d, want 1 and 0", ev[evictExpired], l.ll.Len())
+	}
+
+	// 重新写入刷新过期时间
+	l.set("a", []byte("2"), 0)
+	if _, ok := l.get("a"); !ok {
+		t.Fatal("get(a) miss after set")
+	}
+}
+
+func TestLocal_EntryTTL(t *testing.T) {
+	ev := evictions{}
+	l := newLocal(10, time.Minute, ev.add)
+
+	// 本地保留时长不超过写入时的 ttl
+	l.set("a", []byte("1"), 20*time.Millisecond)
+	l.set("b", []byte("2"), time.Hour)
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := l.get("a"); ok {
+		t.Fatal("get(a) hit after entry ttl")
+	}
+	if _, ok := l.get("b"); !ok {
+		t.Fatal("get(b) miss, want localTTL when ttl is longer")
+	}
+	if ev[evictExpired] != 1 {
+		t.Fatalf("expired evictions = %d, want 1", ev[evictExpired])
+	}
+}
+
+func TestLocal_Delete(t *testing.T) {
+	l := newLocal(10, time.Minute, evictions{}.add)
+
+	l.set("a", []byte("1"), 0)
+	l.set("b", []byte("2"), 0)
+	if n := l.delete("a", "b", "missing"); n != 2 {
+		t.Fatalf("delete() = %d, want 2", n)
+	}
+	if _, ok := l.get("a"); ok {
+		t.Fatal("get(a) hit after delete")
+	}
+	if len(l.items) != 0 || l.ll.Len() != 0 {
+		t.Fatalf("items = %d, list = %d, want empty", len(l.items), l.ll.Len())
+	}
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/metrics"
+)
+
+// Option is cache option.
+type Option func(o *options)
+
+type options struct {
+	// redis name.
+	name string
+	// redis address.
+	addr string
+	// value codec, default json.
+	codec encoding.Codec
+	// timeout of the shared load, default 10s.
+	loadTimeout time.Duration
+	// ttl of not found values, negative caching is disabled when zero.
+	negativeTTL time.Duration
+	// max number of keys in the local tier, local tier is disabled when zero.
+	localSize int
+	// ttl of the local tier.
+	localTTL time.Duration
+	// redis pub/sub channel to broadcast local invalidations.
+	channel string
+	// disabled metrics.
+	disableMetric bool
+	// counter: component_db_system_requests_total{kind,name,addr,command,status}
+	requests metrics.Counter
+}
+
+// WithName with name label.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithAddr with addr label.
+func WithAddr(addr string) Option {
+	return func(o *options) {
+		o.addr = addr
+	}
+}
+
+// WithCodec 设置缓存值的编码方式，如 encoding.GetCodec("json")、encoding.GetCodec("proto")、
+// encoding.GetCodec(MsgpackName)，默认 json
+func WithCodec(codec encoding.Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+// WithLoadTimeout 设置 loader 的超时时间，同一 key 的并发调用共享一次加载，
+// 加载不随单个调用方的 ctx 取消，默认10s
+func WithLoadTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout > 0 {
+			o.loadTimeout = timeout
+		}
+	}
+}
+
+// WithNegativeTTL 开启空值缓存，loader 返回 ErrNotFound 时缓存 ttl 时长，防止缓存穿透
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.negativeTTL = ttl
+	}
+}
+
+// WithLocal 开启进程内缓存，最多缓存 size 个 key，每个 key 在本地最多保留 ttl 且不超过 redis 中的过期时间，
+// 读取时先查本地再查 redis
+func WithLocal(size int, ttl time.Duration) Option {
+	return func(o *options) {
+		o.localSize = size
+		o.localTTL = ttl
+	}
+}
+
+// WithInvalidation 设置失效通知的 redis pub/sub 频道，Set、Delete 时广播 key，
+// 订阅同一频道的其他实例收到后淘汰本地缓存，仅在开启进程内缓存时生效
+func WithInvalidation(channel string) Option {
+	return func(o *options) {
+		o.channel = channel
+	}
+}
+
+// WithDisableMetric set disabled metrics.
+func WithDisableMetric(disabled bool) Option {
+	return func(o *options) {
+		o.disableMetric = disabled
+	}
+}
+
+// WithRequests with requests counter.
+func WithRequests(c metrics.Counter) Option {
+	return func(o *options) {
+		o.requests = c
+	}
+}
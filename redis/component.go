@@ -2,33 +2,61 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	kconfig "github.com/go-kratos/kratos/v2/config"
 	"github.com/nextmicro/logger"
+	"github.com/nextmicro/next-component/internal/connect"
+	"github.com/nextmicro/next-component/internal/tlsconfig"
+	"github.com/nextmicro/next-component/redis/cache"
 	"github.com/nextmicro/next-component/redis/hook/logging"
 	"github.com/nextmicro/next-component/redis/hook/metrics"
+	"github.com/nextmicro/next-component/redis/lock"
+	"github.com/nextmicro/next-component/redis/ratelimit"
 	"github.com/nextmicro/next/config"
 	"github.com/nextmicro/next/runtime/loader"
 	redisotel "github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 )
 
 var (
 	Redis *Component
 	_     loader.Loader = &Component{}
+
+	// ErrInstanceNotFound 命名实例不存在
+	ErrInstanceNotFound = errors.New("instance not found")
+	// ErrNotConnected 延迟连接或非关键实例尚未建立连接
+	ErrNotConnected = errors.New("instance not connected")
 )
 
 type Component struct {
-	opts    map[string]*Options
-	open    bool
-	options []Option
-	stat    *Stat
-	clients sync.Map
+	mu       sync.RWMutex
+	reloadMu sync.Mutex
+	opts     map[string]*Options
+	open     bool
+	options  []Option
+	stat     *Stat
+	statStop context.CancelFunc
+	statDone chan struct{}
+	group    singleflight.Group
+	lazy     connect.Lazy // 延迟连接与非关键实例最近一次连接失败的原因
+	clients  sync.Map
+	drains   sync.Map // 热更新后等待关闭的旧连接 -> *time.Timer
+
+	streamMu     sync.Mutex
+	streams      map[string]*streamWorker // name/stream -> 处理函数
+	streamCancel context.CancelFunc
+	streamWG     sync.WaitGroup
+
+	subs sync.Map // *Subscription -> struct{}
 }
 
 func New(options ...Option) *Component {
@@ -57,30 +85,32 @@ func (c *Component) Init(opts ...loader.Option) error {
 			return errors.New("redis: config not found")
 		}
 
-		for _, option := range cfg.Options() {
-			for _, opt := range c.opts {
-				option.apply(opt)
-			}
-		}
+		// WithConfig 的配置优先于 New 传入的 Option 应用，热更新时同样生效
+		c.options = append(cfg.Options(), c.options...)
 	}
 
-	for _, option := range c.options {
-		for _, opt := range c.opts {
-			option.apply(opt)
-		}
-	}
+	c.apply(c.opts)
 
 	if len(c.opts) == 0 {
 		return nil
 	}
 
 	for name, opt := range c.opts {
+		if opt.Lazy {
+			continue
+		}
+
 		client, err := c.connect(name, opt)
 		if err != nil {
+			if opt.Optional {
+				logger.Errorf("redis: optional instance %s connect error: %v", name, err)
+				c.lazy.Fail(name, err)
+				continue
+			}
 			return err
 		}
 
-		Redis.clients.Store(name, client)
+		c.clients.Store(name, client)
 	}
 
 	c.stat = NewStat(time.Second * 30)
@@ -90,18 +120,170 @@ func (c *Component) Init(opts ...loader.Option) error {
 	return nil
 }
 
+// Get 获取命名实例，name 为空时返回 default 实例，实例不存在时返回 ErrInstanceNotFound
+func (c *Component) Get(name string) (redis.UniversalClient, error) {
+	if name == "" {
+		name = defaultName
+	}
+
+	value, ok := c.clients.Load(name)
+	if !ok {
+		return c.lazyConnect(name)
+	}
+
+	return value.(redis.UniversalClient), nil
+}
+
+// lazyConnect 为延迟连接或启动时连接失败的非关键实例按需建立连接，
+// 通过 singleflight 保证同一实例并发获取时只建立一次连接。按需连接只尝试1次且限制时长，
+// 失败后在冷却期内直接返回上次的错误，避免请求阻塞在启动重试上。
+func (c *Component) lazyConnect(name string) (redis.UniversalClient, error) {
+	opt, ok := c.config(name)
+	if !ok || !(opt.Lazy || opt.Optional) {
+		return nil, fmt.Errorf("redis: %w, group: %s", ErrInstanceNotFound, name)
+	}
+	if err := c.lazy.Err(name); err != nil {
+		return nil, err
+	}
+
+	value, err, _ := c.group.Do(name, func() (interface{}, error) {
+		if value, ok := c.clients.Load(name); ok {
+			return value, nil
+		}
+
+		cfg := *opt
+		cfg.ConnectMaxAttempts, cfg.ConnectTimeout = 1, connect.OnceTimeout(opt.ConnectTimeout)
+		client, err := c.connect(name, &cfg)
+		if err != nil {
+			c.lazy.Fail(name, err)
+			return nil, err
+		}
+
+		c.lazy.Reset(name)
+		c.clients.Store(name, client)
+		return client, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(redis.UniversalClient), nil
+}
+
+// MustGet 获取命名实例，实例不存在时 panic
+func (c *Component) MustGet(name string) redis.UniversalClient {
+	client, err := c.Get(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return client
+}
+
+// Instance 获取命名实例，等同于 MustGet，未传 name 时返回 default 实例
 func (c *Component) Instance(name ...string) redis.UniversalClient {
-	group := defaultName
-	if len(name) > 0 && name[0] != "" {
+	var group string
+	if len(name) > 0 {
 		group = name[0]
 	}
 
-	value, ok := c.clients.Load(group)
+	return c.MustGet(group)
+}
+
+// Locker 获取命名实例的分布式锁客户端，监控与日志按实例配置开启，
+// 实例热更新后旧连接会被关闭，需重新获取
+func (c *Component) Locker(name string) (*lock.Client, error) {
+	if name == "" {
+		name = defaultName
+	}
+
+	client, err := c.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	opt, ok := c.config(name)
 	if !ok {
-		logger.Fatalf("redis: client not found, group: %s", group)
+		return nil, fmt.Errorf("redis: %w, group: %s", ErrInstanceNotFound, name)
 	}
 
-	return value.(redis.UniversalClient)
+	return lock.New(client,
+		lock.WithName(name),
+		lock.WithAddr(strings.Join(opt.Addrs, ",")),
+		lock.WithDisableMetric(opt.DisableMetric),
+		lock.WithDisableLogging(opt.DisableLogging),
+	), nil
+}
+
+// Limiter 获取基于命名实例的分布式限流器，实例热更新后旧连接会被关闭，需重新获取
+func (c *Component) Limiter(name string, opts ...ratelimit.Option) (*ratelimit.Limiter, error) {
+	client, err := c.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return ratelimit.New(client, opts...), nil
+}
+
+// Cache 获取基于命名实例的缓存，监控标签与开关按实例配置设置，开启失效通知时需调用 Close，
+// 实例热更新后旧连接会被关闭，需重新获取
+func (c *Component) Cache(name string, opts ...cache.Option) (*cache.Cache, error) {
+	if name == "" {
+		name = defaultName
+	}
+
+	client, err := c.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	opt, ok := c.config(name)
+	if !ok {
+		return nil, fmt.Errorf("redis: %w, group: %s", ErrInstanceNotFound, name)
+	}
+
+	return cache.New(client, append([]cache.Option{
+		cache.WithName(name),
+		cache.WithAddr(strings.Join(opt.Addrs, ",")),
+		cache.WithDisableMetric(opt.DisableMetric),
+	}, opts...)...), nil
+}
+
+// Health 检查每个命名实例当前是否可用，尚未建立连接的实例返回包装 ErrNotConnected 的错误，
+// 其中非关键实例与尚未使用的延迟连接实例报告为 DEGRADED
+func (c *Component) Health(ctx context.Context) map[string]error {
+	ret := make(map[string]error)
+	c.clients.Range(func(key, value interface{}) bool {
+		ret[key.(string)] = value.(redis.UniversalClient).Ping(ctx).Err()
+		return true
+	})
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for name, opt := range c.opts {
+		if _, ok := ret[name]; !ok {
+			ret[name] = c.lazy.Health(name, opt.Optional, fmt.Errorf("redis: %w, group: %s", ErrNotConnected, name))
+		}
+	}
+	return ret
+}
+
+// apply 将组件选项应用到每个命名实例的配置上
+func (c *Component) apply(opts map[string]*Options) {
+	for _, option := range c.options {
+		for _, opt := range opts {
+			option.apply(opt)
+		}
+	}
+}
+
+// config 返回命名实例当前生效的配置
+func (c *Component) config(name string) (*Options, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	opt, ok := c.opts[name]
+	return opt, ok
 }
 
 func peerInfo(addr string) (hostname string, port int) {
@@ -112,7 +294,10 @@ func peerInfo(addr string) (hostname string, port int) {
 	return hostname, port
 }
 
-func (c *Component) connect(name string, cfg *Options) (redis.UniversalClient, error) {
+func (c *Component) connect(name string, opt *Options) (redis.UniversalClient, error) {
+	// 在副本上补齐默认值，保持 c.opts 为原始配置以便热更新时比较差异
+	cfg := *opt
+	cfg.Hooks = append([]redis.Hook{}, opt.Hooks...)
 	if cfg.PoolSize == 0 {
 		cfg.PoolSize = 10
 	}
@@ -136,17 +321,40 @@ func (c *Component) connect(name string, cfg *Options) (redis.UniversalClient, e
 		logOpt = append(logOpt, logging.WithRequest(cfg.EnableLoggingRequest))
 		logOpt = append(logOpt, logging.WithResponse(cfg.EnableLoggingResponse))
 		logOpt = append(logOpt, logging.WithSlowThreshold(cfg.SlowThreshold))
+		redactor := cfg.LoggingRedactor
+		if redactor == nil {
+			redactor = logging.NewRedaction(cfg.LoggingRedactRules, cfg.LoggingMaxArgLen, cfg.LoggingMaxResponseLen)
+		}
+		logOpt = append(logOpt, logging.WithRedactor(redactor))
 		cfg.Hooks = append(cfg.Hooks, logging.NewLogging(logOpt...))
 	}
 
+	if cfg.ConnMaxIdleTime == 0 {
+		cfg.ConnMaxIdleTime = cfg.IdleTimeout
+	}
+	// 未单独配置哨兵凭证时沿用实例凭证
+	if cfg.SentinelUsername == "" {
+		cfg.SentinelUsername = cfg.Username
+	}
+	if cfg.SentinelPassword == "" {
+		cfg.SentinelPassword = cfg.Password
+	}
+	var tlsConfig *tls.Config
+	if cfg.TLS != nil {
+		var err error
+		if tlsConfig, err = tlsconfig.Load(cfg.TLS); err != nil {
+			return nil, fmt.Errorf("redis: %s tls %w", name, err)
+		}
+	}
+
 	client := redis.NewUniversalClient(&redis.UniversalOptions{
 		Addrs:                 cfg.Addrs,
 		ClientName:            cfg.ClientName,
 		DB:                    cfg.DB,
 		Username:              cfg.Username,
 		Password:              cfg.Password,
-		SentinelUsername:      cfg.Username,
-		SentinelPassword:      cfg.Password,
+		SentinelUsername:      cfg.SentinelUsername,
+		SentinelPassword:      cfg.SentinelPassword,
 		MaxRetries:            cfg.MaxRetries,
 		MinRetryBackoff:       cfg.MinRetryBackoff,
 		MaxRetryBackoff:       cfg.MaxRetryBackoff,
@@ -159,7 +367,10 @@ func (c *Component) connect(name string, cfg *Options) (redis.UniversalClient, e
 		PoolTimeout:           cfg.PoolTimeout,
 		MinIdleConns:          cfg.MinIdleConns,
 		MaxIdleConns:          cfg.MaxIdleConns,
+		ConnMaxIdleTime:       cfg.ConnMaxIdleTime,
+		ConnMaxLifetime:       cfg.ConnMaxLifetime,
 		MaxRedirects:          cfg.MaxRetries,
+		TLSConfig:             tlsConfig,
 		ReadOnly:              cfg.ReadOnly,
 		RouteByLatency:        cfg.RouteByLatency,
 		RouteRandomly:         cfg.RouteRandomly,
@@ -172,11 +383,16 @@ func (c *Component) connect(name string, cfg *Options) (redis.UniversalClient, e
 	// Enable tracing instrumentation.
 	if !cfg.DisableTrace {
 		if err := redisotel.InstrumentTracing(client); err != nil {
+			_ = client.Close()
 			return nil, err
 		}
 	}
 
-	if err := client.Ping(context.Background()).Err(); err != nil {
+	err := retry(name, &cfg, func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	})
+	if err != nil {
+		_ = client.Close()
 		return nil, err
 	}
 
@@ -189,24 +405,188 @@ func (c *Component) Open() bool {
 }
 
 func (c *Component) Start(ctx context.Context) error {
-	go c.stat.Run(ctx)
+	if c.stat != nil {
+		ctx, cancel := context.WithCancel(ctx)
+		c.statStop, c.statDone = cancel, make(chan struct{})
+		go func() {
+			defer close(c.statDone)
+			c.stat.Run(ctx)
+		}()
+	}
+
+	if err := c.startStreams(ctx); err != nil {
+		return err
+	}
 
 	logger.Infof("Component [%s] Start success", c.String())
 	return nil
 }
 
+// Watch 监听 go-redis 配置变化，仅重建发生变化的实例
 func (c *Component) Watch() error {
-	return nil
+	if !c.open {
+		return nil
+	}
+
+	return config.Watch(namespace, c.watch)
+}
+
+func (c *Component) watch(_ string, value kconfig.Value) {
+	opts := make(map[string]*Options)
+	if err := value.Scan(&opts); err != nil {
+		logger.Errorf("redis: watch scan config error: %v", err)
+		return
+	}
+
+	c.apply(opts)
+	c.reload(opts)
 }
 
+// reload 对比新旧配置，为变更或新增的实例创建新连接并原子替换，
+// 被替换或删除的旧连接在宽限期后关闭，未变更的实例保持原有连接。
+func (c *Component) reload(opts map[string]*Options) {
+	// 建立连接可能按 connect_timeout 重试，期间只持有 reloadMu，不阻塞读取配置
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	for name, opt := range opts {
+		old, ok := c.opts[name]
+		if ok && equal(old, opt) {
+			continue
+		}
+		c.lazy.Reset(name)
+
+		// 延迟连接的实例只移除旧连接，下次获取时按新配置建立连接
+		if opt.Lazy {
+			if prev, loaded := c.clients.LoadAndDelete(name); loaded {
+				c.drain(name, prev.(redis.UniversalClient), opt.GracePeriod)
+			}
+			continue
+		}
+
+		client, err := c.connect(name, opt)
+		if err != nil {
+			logger.Errorf("redis: reload %s error: %v", name, err)
+			if ok {
+				opts[name] = old
+			} else {
+				delete(opts, name)
+			}
+			continue
+		}
+
+		if prev, loaded := c.clients.Swap(name, client); loaded {
+			c.drain(name, prev.(redis.UniversalClient), opt.GracePeriod)
+		}
+		logger.Infof("%s %s reload success", namespace, name)
+	}
+
+	for name, old := range c.opts {
+		if _, ok := opts[name]; ok {
+			continue
+		}
+
+		if prev, loaded := c.clients.LoadAndDelete(name); loaded {
+			c.drain(name, prev.(redis.UniversalClient), old.GracePeriod)
+		}
+		c.lazy.Reset(name)
+		logger.Infof("%s %s removed", namespace, name)
+	}
+
+	c.mu.Lock()
+	c.opts = opts
+	c.mu.Unlock()
+}
+
+// equal 比较两份配置是否一致，钩子与自定义脱敏实现无法比较，由组件选项统一注入，不参与比较
+func equal(a, b *Options) bool {
+	x, y := *a, *b
+	x.Hooks, y.Hooks = nil, nil
+	x.LoggingRedactor, y.LoggingRedactor = nil, nil
+	return reflect.DeepEqual(x, y)
+}
+
+// drain 在宽限期后关闭旧连接，等待使用旧连接的请求执行完成
+func (c *Component) drain(name string, client redis.UniversalClient, grace time.Duration) {
+	if grace == 0 {
+		grace = defaultGracePeriod
+	}
+
+	timer := time.AfterFunc(grace, func() {
+		c.drains.Delete(client)
+		if err := client.Close(); err != nil {
+			logger.Errorf("redis: close %s old client error: %v", name, err)
+			return
+		}
+		logger.Infof("%s %s old client closed", namespace, name)
+	})
+	c.drains.Store(client, timer)
+}
+
+// Stop 停止 stream 消费、取消订阅、停止统计协程并关闭全部连接（含热更新后尚在宽限期内的旧连接），
+// ctx 结束时不再等待并返回超时错误，关闭失败的错误会被合并返回
 func (c *Component) Stop(ctx context.Context) error {
+	// stream 消费与订阅超时未退出时仍继续关闭连接
+	var errs []error
+	if err := c.stopStreams(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.closeSubscriptions(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.statStop != nil {
+		c.statStop()
+		select {
+		case <-c.statDone:
+		case <-ctx.Done():
+		}
+	}
+
+	var clients []redis.UniversalClient
+	names := make(map[redis.UniversalClient]string)
 	c.clients.Range(func(key, value interface{}) bool {
-		client := value.(*redis.Client)
-		_ = client.Close()
+		client := value.(redis.UniversalClient)
+		clients = append(clients, client)
+		names[client] = key.(string)
+		c.clients.Delete(key)
+		return true
+	})
+	c.drains.Range(func(key, value interface{}) bool {
+		// 定时器已触发的旧连接由定时器负责关闭
+		if value.(*time.Timer).Stop() {
+			clients = append(clients, key.(redis.UniversalClient))
+		}
+		c.drains.Delete(key)
 		return true
 	})
 
-	c.clients = sync.Map{}
+	errc := make(chan error, len(clients))
+	for _, client := range clients {
+		go func(client redis.UniversalClient) {
+			if err := client.Close(); err != nil {
+				name, ok := names[client]
+				if !ok {
+					name = "old"
+				}
+				errc <- fmt.Errorf("redis: close %s %w", name, err)
+				return
+			}
+			errc <- nil
+		}(client)
+	}
+
+	for range clients {
+		select {
+		case err := <-errc:
+			errs = append(errs, err)
+		case <-ctx.Done():
+			return errors.Join(append(errs, fmt.Errorf("redis: stop %w", ctx.Err()))...)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
 
 	logger.Infof("Component [%s] stop success", c.String())
 	return nil
@@ -0,0 +1,266 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestInit 按配置初始化组件，关闭监控、链路与日志
+func newTestInit(t *testing.T, content string) *Component {
+	t.Helper()
+
+	loadConfig(t, content)
+	c := New(WithDisableMetric(), WithDisableTrace(), WithDisableLogging())
+	if err := c.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop(context.Background()) })
+	return c
+}
+
+// reloadOptions 复制当前配置并应用组件选项，模拟配置变更后的 watch
+func reloadOptions(c *Component, fn func(opts map[string]*Options)) map[string]*Options {
+	c.mu.RLock()
+	opts := make(map[string]*Options, len(c.opts))
+	for name, opt := range c.opts {
+		cfg := *opt
+		opts[name] = &cfg
+	}
+	c.mu.RUnlock()
+
+	fn(opts)
+	c.apply(opts)
+	return opts
+}
+
+func closed(client redis.UniversalClient) bool {
+	return errors.Is(client.Ping(context.Background()).Err(), redis.ErrClosed)
+}
+
+func waitClosed(t *testing.T, clients ...redis.UniversalClient) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for _, client := range clients {
+		for !closed(client) {
+			if time.Now().After(deadline) {
+				t.Fatal("client not closed")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+}
+
+func TestComponent_Reload(t *testing.T) {
+	mr1, mr2 := miniredis.RunT(t), miniredis.RunT(t)
+	c := newTestInit(t, fmt.Sprintf(`{"go-redis":{"default":{"addrs":["%s"],"grace_period":20000000}}}`, mr1.Addr()))
+	old := c.MustGet("")
+
+	// 只变更钩子与脱敏实现时不重新连接
+	c.reload(reloadOptions(c, func(opts map[string]*Options) {
+		opts[defaultName].Hooks = []redis.Hook{nopHook{}}
+	}))
+	if c.MustGet("") != old {
+		t.Fatal("hook-only change reconnected")
+	}
+
+	// 地址变更后获取到新连接，旧连接在宽限期后关闭
+	c.reload(reloadOptions(c, func(opts map[string]*Options) {
+		opts[defaultName].Addrs = []string{mr2.Addr()}
+	}))
+	client := c.MustGet("")
+	if client == old {
+		t.Fatal("Get() returned the old client after reload")
+	}
+	if err := client.Set(context.Background(), "key", "value", 0).Err(); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if !mr2.Exists("key") || mr1.Exists("key") {
+		t.Fatal("new client not connected to the new address")
+	}
+	if closed(old) {
+		t.Fatal("old client closed before the grace period")
+	}
+	waitClosed(t, old)
+
+	// 连接失败时保留原有连接与配置
+	c.reload(reloadOptions(c, func(opts map[string]*Options) {
+		opts[defaultName].Addrs = []string{closedAddr(t)}
+	}))
+	if c.MustGet("") != client {
+		t.Fatal("failed reload replaced the client")
+	}
+	if opt, _ := c.config(defaultName); opt.Addrs[0] != mr2.Addr() {
+		t.Fatalf("failed reload kept addrs %v, want %s", opt.Addrs, mr2.Addr())
+	}
+}
+
+// closedAddr 返回一个没有监听的本地地址
+func closedAddr(t *testing.T) string {
+	mr := miniredis.RunT(t)
+	addr := mr.Addr()
+	mr.Close()
+	return addr
+}
+
+func TestComponent_Stop(t *testing.T) {
+	mr1, mr2 := miniredis.RunT(t), miniredis.RunT(t)
+	c := newTestInit(t, fmt.Sprintf(`{"go-redis":{"default":{"addrs":["%s"],"grace_period":3600000000000},"backup":{"addrs":["%s"]}}}`, mr1.Addr(), mr2.Addr()))
+	old, backup := c.MustGet(""), c.MustGet("backup")
+
+	// 热更新后旧连接在宽限期内等待关闭
+	c.reload(reloadOptions(c, func(opts map[string]*Options) {
+		opts[defaultName].Addrs = []string{mr2.Addr()}
+	}))
+	client := c.MustGet("")
+
+	if err := c.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	for _, cl := range []redis.UniversalClient{old, backup, client} {
+		if !closed(cl) {
+			t.Fatal("Stop() did not close every client")
+		}
+	}
+	c.drains.Range(func(_, _ interface{}) bool {
+		t.Fatal("Stop() left pending drains")
+		return false
+	})
+	if _, err := c.Get(""); err == nil {
+		t.Fatal("Get() after Stop returned a client")
+	}
+}
+
+func TestComponent_StopDeadline(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newTestInit(t, fmt.Sprintf(`{"go-redis":{"default":{"addrs":["%s"]}}}`, mr.Addr()))
+	client := c.MustGet("")
+
+	// 处理中的消息阻塞取消订阅
+	handling, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	if _, err := c.Subscribe(context.Background(), "", []string{"events"}, func(context.Context, *PubSubMessage) error {
+		close(handling)
+		<-release
+		return nil
+	}); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if err := c.Publish(context.Background(), "", "events", []byte("payload")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	<-handling
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stop() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	// 超时后仍关闭连接
+	waitClosed(t, client)
+}
+
+func TestComponent_InstanceNotFound(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newTestInit(t, fmt.Sprintf(`{"go-redis":{"default":{"addrs":["%s"]}}}`, mr.Addr()))
+
+	if _, err := c.Get(""); err != nil {
+		t.Fatalf("Get(default) error = %v", err)
+	}
+
+	_, err := c.Get("missing")
+	if !errors.Is(err, ErrInstanceNotFound) {
+		t.Fatalf("Get(missing) error = %v, want %v", err, ErrInstanceNotFound)
+	}
+	if _, err = c.Locker("missing"); !errors.Is(err, ErrInstanceNotFound) {
+		t.Fatalf("Locker(missing) error = %v, want %v", err, ErrInstanceNotFound)
+	}
+	if _, err = c.Limiter("missing"); !errors.Is(err, ErrInstanceNotFound) {
+		t.Fatalf("Limiter(missing) error = %v, want %v", err, ErrInstanceNotFound)
+	}
+	if _, err = c.Cache("missing"); !errors.Is(err, ErrInstanceNotFound) {
+		t.Fatalf("Cache(missing) error = %v, want %v", err, ErrInstanceNotFound)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("MustGet(missing) did not panic")
+		}
+	}()
+	c.MustGet("missing")
+}
+
+func TestComponent_Health(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newTestInit(t, fmt.Sprintf(`{"go-redis":{"default":{"addrs":["%s"],"max_retries":-1}}}`, mr.Addr()))
+
+	if err := c.Health(context.Background())[defaultName]; err != nil {
+		t.Fatalf("Health() = %v, want nil", err)
+	}
+
+	// redis 不可用时报告为 DOWN
+	mr.Close()
+	err := c.Health(context.Background())[defaultName]
+	var d interface{ Degraded() bool }
+	if err == nil || errors.As(err, &d) {
+		t.Fatalf("Health() after close = %v, want a down error", err)
+	}
+}
+
+func TestComponent_LazyAndOptional(t *testing.T) {
+	mr := miniredis.RunT(t)
+	down := closedAddr(t)
+	c := newTestInit(t, fmt.Sprintf(`{"go-redis":{"default":{"addrs":["%s"],"lazy":true},"failing":{"addrs":["%s"],"lazy":true},"backup":{"addrs":["%s"],"optional":true}}}`,
+		mr.Addr(), down, down))
+	degraded := func(err error) bool {
+		var d interface{ Degraded() bool }
+		return errors.As(err, &d) && d.Degraded()
+	}
+
+	// 尚未连接的延迟连接实例与连接失败的非关键实例报告为 DEGRADED
+	health := c.Health(context.Background())
+	for _, name := range []string{defaultName, "failing", "backup"} {
+		if err := health[name]; !errors.Is(err, ErrNotConnected) || !degraded(err) {
+			t.Fatalf("Health() %s = %v, want degraded not connected", name, err)
+		}
+	}
+	if mr.CurrentConnectionCount() != 0 {
+		t.Fatal("lazy instance connected on Init")
+	}
+
+	// 首次获取时建立连接
+	if _, err := c.Get(""); err != nil {
+		t.Fatalf("Get() lazy error = %v", err)
+	}
+	if err := c.Health(context.Background())[defaultName]; err != nil {
+		t.Fatalf("Health() connected lazy = %v, want nil", err)
+	}
+
+	// 按需连接失败后，冷却期内直接返回上次的错误
+	_, err := c.Get("failing")
+	if err == nil || errors.Is(err, ErrInstanceNotFound) {
+		t.Fatalf("Get(failing) error = %v, want connect error", err)
+	}
+	start := time.Now()
+	if _, again := c.Get("failing"); again != err {
+		t.Fatalf("Get(failing) in cooldown = %v, want %v", again, err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("Get(failing) in cooldown took %v", elapsed)
+	}
+
+	// 连接失败的延迟连接实例报告为 DOWN，非关键实例仍为 DEGRADED
+	health = c.Health(context.Background())
+	if err := health["failing"]; !errors.Is(err, ErrNotConnected) || degraded(err) {
+		t.Fatalf("Health() failing lazy = %v, want down", err)
+	}
+	if err := health["backup"]; !errors.Is(err, ErrNotConnected) || !degraded(err) {
+		t.Fatalf("Health() optional = %v, want degraded", err)
+	}
+}
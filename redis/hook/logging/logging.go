@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/nextmicro/gokit/timex"
+	"github.com/nextmicro/logger"
 	rediscmd "github.com/redis/go-redis/extra/rediscmd/v9"
 	"github.com/redis/go-redis/v9"
 )
@@ -22,6 +23,7 @@ type options struct {
 	Request       bool
 	Response      bool
 	SlowThreshold time.Duration
+	Redactor      Redactor
 }
 
 type logging struct {
@@ -46,11 +48,21 @@ func WithSlowThreshold(threshold time.Duration) Option {
 	}
 }
 
+// WithRedactor 设置记录日志前的脱敏方式，默认隐藏 AUTH、HELLO 的参数
+func WithRedactor(r Redactor) Option {
+	return func(o *options) {
+		if r != nil {
+			o.Redactor = r
+		}
+	}
+}
+
 func NewLogging(opts ...Option) redis.Hook {
 	opt := &options{
 		Request:       false,
 		Response:      false,
 		SlowThreshold: time.Millisecond * 100,
+		Redactor:      NewRedaction(nil, 0, 0),
 	}
 	for _, o := range opts {
 		o(opt)
@@ -82,6 +94,13 @@ func response(cmd redis.Cmder) string {
 	}
 }
 
+// redact 返回参数脱敏后的命令副本，仅用于记录日志
+func (l *logging) redact(ctx context.Context, cmd redis.Cmder) redis.Cmder {
+	c := redis.NewCmd(ctx, l.opt.Redactor.RedactArgs(cmd.Args())...)
+	c.SetErr(cmd.Err())
+	return c
+}
+
 func (l *logging) DialHook(hook redis.DialHook) redis.DialHook {
 	return func(ctx context.Context, network, addr string) (net.Conn, error) {
 		now := time.Now()
@@ -115,18 +134,19 @@ func (l *logging) ProcessHook(hook redis.ProcessHook) redis.ProcessHook {
 
 		err := hook(ctx, cmd)
 		duration := time.Since(now)
+		redacted := l.redact(ctx, cmd)
 		fields := map[string]interface{}{
 			"kind":      "db",
 			"component": component,
 			"method":    cmd.FullName(),
-			"sql":       rediscmd.CmdStrins(cmd),
+			"sql":       rediscmd.CmdString(redacted),
 			"duration":  timex.Duration(duration),
 		}
 		if l.opt.Request {
-			fields["request"] = cmd.Args()
+			fields["request"] = redacted.Args()
 		}
 		if l.opt.Response {
-			fields["response"] = response(cmd)
+			fields["response"] = l.opt.Redactor.RedactResponse(cmd.Args(), response(cmd))
 		}
 		if err != nil && !errors.Is(err, redis.Nil) {
 			fields["error"] = err
@@ -142,20 +162,25 @@ func (l *logging) ProcessHook(hook redis.ProcessHook) redis.ProcessHook {
 		} else {
 			log.Info("[REDIS] Client")
 		}
+
+		return err
 	}
 }
 
 func (l *logging) ProcessPipelineHook(hook redis.ProcessPipelineHook) redis.ProcessPipelineHook {
 	return func(ctx context.Context, cmds []redis.Cmder) error {
 		if len(cmds) == 0 {
-			return nil
+			return hook(ctx, cmds)
 		}
 
 		now := time.Now()
 		err := hook(ctx, cmds)
 
-		cmd := cmds[0]
-		cmdName, sql := rediscmd.CmdsString(cmds)
+		redacted := make([]redis.Cmder, len(cmds))
+		for i, cmd := range cmds {
+			redacted[i] = l.redact(ctx, cmd)
+		}
+		cmdName, sql := rediscmd.CmdsString(redacted)
 
 		duration := time.Since(now)
 		fields := map[string]interface{}{
@@ -163,13 +188,34 @@ func (l *logging) ProcessPipelineHook(hook redis.ProcessPipelineHook) redis.Proc
 			"component": component,
 			"method":    cmdName,
 			"statement": sql,
+			"size":      len(cmds),
 			"duration":  timex.Duration(duration),
 		}
+		// 按命令名汇总执行次数与失败次数，pipeline 整体成功时其中的命令仍可能失败
+		commands, failures := make(map[string]int), make(map[string]int)
+		for _, cmd := range cmds {
+			commands[cmd.Name()]++
+			if cmdErr := cmd.Err(); cmdErr != nil && !errors.Is(cmdErr, redis.Nil) {
+				failures[cmd.Name()]++
+			}
+		}
+		fields["commands"] = commands
+		if len(failures) > 0 {
+			fields["failures"] = failures
+		}
 		if l.opt.Request {
-			fields["request"] = cmd.Args()
+			request := make([][]interface{}, len(redacted))
+			for i, cmd := range redacted {
+				request[i] = cmd.Args()
+			}
+			fields["request"] = request
 		}
 		if l.opt.Response {
-			fields["response"] = response(cmd)
+			resp := make([]string, len(cmds))
+			for i, cmd := range cmds {
+				resp[i] = l.opt.Redactor.RedactResponse(cmd.Args(), response(cmd))
+			}
+			fields["response"] = resp
 		}
 		if err != nil && !errors.Is(err, redis.Nil) {
 			fields["error"] = err
@@ -186,6 +232,6 @@ func (l *logging) ProcessPipelineHook(hook redis.ProcessPipelineHook) redis.Proc
 			log.Info("redis client")
 		}
 
-		return nil
+		return err
 	}
 }
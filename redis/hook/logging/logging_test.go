@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// errHook 在 pipeline 执行后返回固定错误，模拟网络错误
+type errHook struct{ err error }
+
+func (errHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (errHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook { return next }
+
+func (h errHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		_ = next(ctx, cmds)
+		return h.err
+	}
+}
+
+func TestLogging_ProcessPipelineHookError(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	// 日志钩子在外层，返回内层 pipeline 的错误
+	errPipeline := errors.New("pipeline failed")
+	client.AddHook(NewLogging(WithRequest(true), WithResponse(true)))
+	client.AddHook(errHook{err: errPipeline})
+
+	ctx := context.Background()
+	_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, "a", "1", 0)
+		pipe.Get(ctx, "a")
+		return nil
+	})
+	if !errors.Is(err, errPipeline) {
+		t.Fatalf("Pipelined() error = %v, want %v", err, errPipeline)
+	}
+
+}
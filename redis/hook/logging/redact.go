@@ -0,0 +1,183 @@
+package logging
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Mask 脱敏后的占位符
+const Mask = "******"
+
+// Redactor 在记录日志前对命令参数与响应脱敏
+type Redactor interface {
+	// RedactArgs 返回用于记录的命令参数，不得修改传入的 args
+	RedactArgs(args []interface{}) []interface{}
+	// RedactResponse 返回用于记录的响应，args 为原始命令参数
+	RedactResponse(args []interface{}, response string) string
+}
+
+// Rule 单条命令的脱敏规则
+type Rule struct {
+	Command  string   `json:"command"`  // 命令名，不区分大小写
+	Keys     []string `json:"keys"`     // key 匹配模式，语法同 path.Match，为空时匹配全部 key
+	Hide     bool     `json:"hide"`     // 隐藏命令名之外的全部参数
+	Values   bool     `json:"values"`   // 脱敏写入的值，HSET 等只脱敏 value 不脱敏 field
+	Response bool     `json:"response"` // 脱敏响应
+}
+
+// DefaultRules 默认规则，隐藏 AUTH 与 HELLO 携带的凭证
+var DefaultRules = []Rule{
+	{Command: "auth", Hide: true},
+	{Command: "hello", Hide: true},
+}
+
+// Redaction 基于规则的 Redactor，超出长度的参数与响应会被截断
+type Redaction struct {
+	Rules          []Rule // 脱敏规则，同一命令匹配多条规则时依次生效
+	MaxArgLen      int    // 单个参数最大记录长度，0 为不限制
+	MaxResponseLen int    // 响应最大记录长度，0 为不限制
+}
+
+// NewRedaction 创建基于规则的 Redactor，DefaultRules 总是生效
+func NewRedaction(rules []Rule, maxArgLen, maxResponseLen int) *Redaction {
+	return &Redaction{
+		Rules:          append(append([]Rule{}, DefaultRules...), rules...),
+		MaxArgLen:      maxArgLen,
+		MaxResponseLen: maxResponseLen,
+	}
+}
+
+func (r *Redaction) RedactArgs(args []interface{}) []interface{} {
+	if len(args) == 0 {
+		return args
+	}
+
+	name := strings.ToLower(fmt.Sprint(args[0]))
+	out := make([]interface{}, len(args))
+	copy(out, args)
+	for _, rule := range r.Rules {
+		if !strings.EqualFold(rule.Command, name) {
+			continue
+		}
+		if rule.Hide && hit(rule, args) {
+			if len(out) > 1 {
+				out = append(out[:1], Mask)
+			}
+			break
+		}
+		if rule.Values {
+			maskValues(name, rule.Keys, out)
+		}
+	}
+
+	if r.MaxArgLen > 0 {
+		for i, arg := range out {
+			if s, ok := stringArg(arg); ok && s != Mask {
+				out[i] = truncate(s, r.MaxArgLen)
+			}
+		}
+	}
+	return out
+}
+
+func (r *Redaction) RedactResponse(args []interface{}, response string) string {
+	if len(args) > 0 {
+		name := fmt.Sprint(args[0])
+		for _, rule := range r.Rules {
+			if !strings.EqualFold(rule.Command, name) {
+				continue
+			}
+			if (rule.Hide || rule.Response) && hit(rule, args) {
+				return Mask
+			}
+		}
+	}
+
+	if r.MaxResponseLen > 0 {
+		return truncate(response, r.MaxResponseLen)
+	}
+	return response
+}
+
+// maskValues 按命令的参数布局脱敏 key 匹配的值
+func maskValues(name string, patterns []string, args []interface{}) {
+	switch name {
+	case "mset", "msetnx":
+		// MSET key value [key value ...]
+		for i := 1; i+1 < len(args); i += 2 {
+			if match(patterns, args[i]) {
+				args[i+1] = Mask
+			}
+		}
+	case "set", "setnx", "getset":
+		// SET key value [EX seconds ...]，保留过期时间等选项
+		if len(args) > 2 && match(patterns, args[1]) {
+			args[2] = Mask
+		}
+	case "setex", "psetex":
+		// SETEX key seconds value
+		if len(args) > 3 && match(patterns, args[1]) {
+			args[3] = Mask
+		}
+	case "hset", "hmset", "hsetnx":
+		// HSET key field value [field value ...]
+		if len(args) < 2 || !match(patterns, args[1]) {
+			return
+		}
+		for i := 3; i < len(args); i += 2 {
+			args[i] = Mask
+		}
+	default:
+		// LPUSH key element [...]、SADD key member [...] 等，脱敏 key 之后的全部参数
+		if len(args) < 2 || !match(patterns, args[1]) {
+			return
+		}
+		for i := 2; i < len(args); i++ {
+			args[i] = Mask
+		}
+	}
+}
+
+// hit 判断规则的 key 模式是否匹配命令的 key，未配置 key 模式时总是匹配
+func hit(rule Rule, args []interface{}) bool {
+	if len(rule.Keys) == 0 {
+		return true
+	}
+	return len(args) > 1 && match(rule.Keys, args[1])
+}
+
+func match(patterns []string, key interface{}) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	k := fmt.Sprint(key)
+	if b, ok := key.([]byte); ok {
+		k = string(b)
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, k); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func stringArg(arg interface{}) (string, bool) {
+	switch v := arg.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	default:
+		return "", false
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return fmt.Sprintf("%s...(%d bytes)", s[:n], len(s))
+}
@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedaction_RedactArgs(t *testing.T) {
+	r := NewRedaction([]Rule{
+		{Command: "set", Keys: []string{"token:*"}, Values: true},
+		{Command: "setex", Keys: []string{"token:*"}, Values: true},
+		{Command: "hset", Keys: []string{"user:*"}, Values: true},
+		{Command: "mset", Keys: []string{"secret:*"}, Values: true},
+		{Command: "lpush", Values: true},
+		{Command: "get", Keys: []string{"session:*"}, Hide: true},
+	}, 0, 0)
+
+	tests := []struct {
+		name string
+		args []interface{}
+		want []interface{}
+	}{
+		{"auth hidden", []interface{}{"auth", "user", "pass"}, []interface{}{"auth", Mask}},
+		{"hello hidden", []interface{}{"hello", 3, "auth", "user", "pass"}, []interface{}{"hello", Mask}},
+		{"auth case insensitive", []interface{}{"AUTH", "pass"}, []interface{}{"AUTH", Mask}},
+		{"set matched key", []interface{}{"set", "token:1", "v", "ex", 10}, []interface{}{"set", "token:1", Mask, "ex", 10}},
+		{"set other key", []interface{}{"set", "other", "v"}, []interface{}{"set", "other", "v"}},
+		{"setex value", []interface{}{"setex", "token:1", 10, "v"}, []interface{}{"setex", "token:1", 10, Mask}},
+		{"hset values only", []interface{}{"hset", "user:1", "name", "a", "phone", "b"}, []interface{}{"hset", "user:1", "name", Mask, "phone", Mask}},
+		{"hset other key", []interface{}{"hset", "h", "f", "v"}, []interface{}{"hset", "h", "f", "v"}},
+		{"mset per pair", []interface{}{"mset", "secret:1", "a", "plain", "b"}, []interface{}{"mset", "secret:1", Mask, "plain", "b"}},
+		{"lpush any key", []interface{}{"lpush", "q", "a", "b"}, []interface{}{"lpush", "q", Mask, Mask}},
+		{"hide matched key", []interface{}{"get", "session:1"}, []interface{}{"get", Mask}},
+		{"hide other key", []interface{}{"get", "other"}, []interface{}{"get", "other"}},
+		{"byte key", []interface{}{"set", []byte("token:2"), "v"}, []interface{}{"set", []byte("token:2"), Mask}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]interface{}{}, tt.args...)
+			if got := r.RedactArgs(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("RedactArgs(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Fatalf("RedactArgs modified args: %v", tt.args)
+			}
+		})
+	}
+}
+
+func TestRedaction_Truncate(t *testing.T) {
+	r := NewRedaction([]Rule{{Command: "set", Values: true}}, 4, 3)
+
+	got := r.RedactArgs([]interface{}{"rpush", "queue", []byte("abc"), 123456})
+	want := []interface{}{"rpus...(5 bytes)", "queu...(5 bytes)", "abc", 123456}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("RedactArgs = %v, want %v", got, want)
+	}
+	// 脱敏占位符不被截断
+	if got = r.RedactArgs([]interface{}{"set", "k", "value"}); got[2] != Mask {
+		t.Fatalf("masked value = %v", got[2])
+	}
+	if resp := r.RedactResponse([]interface{}{"get", "k"}, "abcdef"); resp != "abc...(6 bytes)" {
+		t.Fatalf("RedactResponse = %q", resp)
+	}
+}
+
+func TestRedaction_RedactResponse(t *testing.T) {
+	r := NewRedaction([]Rule{
+		{Command: "get", Keys: []string{"token:*"}, Response: true},
+		{Command: "hgetall", Response: true},
+		{Command: "set", Keys: []string{"token:*"}, Values: true},
+	}, 0, 0)
+
+	tests := []struct {
+		name string
+		args []interface{}
+		resp string
+		want string
+	}{
+		{"matched key", []interface{}{"get", "token:1"}, "secret", Mask},
+		{"other key", []interface{}{"get", "other"}, "plain", "plain"},
+		{"any key", []interface{}{"hgetall", "h"}, "map[a:b]", Mask},
+		{"values rule keeps response", []interface{}{"set", "token:1", "v"}, "OK", "OK"},
+		{"auth hidden", []interface{}{"auth", "pass"}, "OK", Mask},
+		{"empty args", nil, "OK", "OK"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.RedactResponse(tt.args, tt.resp); got != tt.want {
+				t.Fatalf("RedactResponse(%v) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
@@ -26,6 +26,9 @@ func NewMetricHook(opts ...Option) redis.Hook {
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	if cfg.sizes == nil {
+		cfg.sizes = cfg.seconds
+	}
 
 	return &MetricHook{
 		opt: cfg,
@@ -56,20 +59,39 @@ func (m *MetricHook) ProcessHook(hook redis.ProcessHook) redis.ProcessHook {
 	}
 }
 
+// ProcessPipelineHook 以 pipeline 为单位记录请求数与耗时，command 为 pipeline；
+// 以 command 为 pipeline.size 记录 pipeline 中的命令数，观测值为命令数而非耗时；
+// 同时以 command 为 pipeline.<命令名> 记录其中每条命令的执行结果，pipeline 整体成功时其中的命令仍可能失败
 func (m *MetricHook) ProcessPipelineHook(hook redis.ProcessPipelineHook) redis.ProcessPipelineHook {
 	return func(ctx context.Context, cmds []redis.Cmder) error {
 		var (
-			cmder = cmds[0]
-			code  = codes.Ok
+			code = codes.Ok
 		)
 		now := time.Now()
 		err := hook(ctx, cmds)
 		if err != nil && !errors.Is(err, redis.Nil) {
 			code = codes.Error
 		}
+		if len(cmds) == 0 {
+			return err
+		}
 
-		m.opt.requests.With(component, m.opt.name, m.opt.addr, cmder.Name(), code.String()).Inc()
-		m.opt.seconds.With(component, m.opt.name, m.opt.addr, cmder.Name()).Observe(float64(time.Since(now).Milliseconds()))
+		m.opt.requests.With(component, m.opt.name, m.opt.addr, "pipeline", code.String()).Inc()
+		m.opt.seconds.With(component, m.opt.name, m.opt.addr, "pipeline").Observe(float64(time.Since(now).Milliseconds()))
+		m.opt.sizes.With(component, m.opt.name, m.opt.addr, "pipeline.size").Observe(float64(len(cmds)))
+
+		type key struct{ command, status string }
+		counts := make(map[key]int)
+		for _, cmd := range cmds {
+			status := codes.Ok
+			if err := cmd.Err(); err != nil && !errors.Is(err, redis.Nil) {
+				status = codes.Error
+			}
+			counts[key{cmd.Name(), status.String()}]++
+		}
+		for k, n := range counts {
+			m.opt.requests.With(component, m.opt.name, m.opt.addr, "pipeline."+k.command, k.status).Add(float64(n))
+		}
 
 		return err
 	}
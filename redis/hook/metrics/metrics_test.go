@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// recorder 按标签记录计数，用于断言写入的监控
+type recorder struct {
+	mu     sync.Mutex
+	values map[string]float64
+	lvs    []string
+}
+
+func newRecorder() *recorder {
+	return &recorder{values: make(map[string]float64)}
+}
+
+func (r *recorder) With(lvs ...string) metrics.Counter {
+	return &recorder{values: r.values, lvs: lvs}
+}
+
+func (r *recorder) Inc() { r.Add(1) }
+
+func (r *recorder) Add(delta float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[strings.Join(r.lvs, ",")] += delta
+}
+
+type observer struct{}
+
+func (observer) With(...string) metrics.Observer { return observer{} }
+func (observer) Observe(float64)                 {}
+
+// histogram 按标签记录观测值
+type histogram struct {
+	values map[string][]float64
+	lvs    []string
+}
+
+func (h *histogram) With(lvs ...string) metrics.Observer {
+	return &histogram{values: h.values, lvs: lvs}
+}
+
+func (h *histogram) Observe(v float64) {
+	k := strings.Join(h.lvs, ",")
+	h.values[k] = append(h.values[k], v)
+}
+
+func TestMetricHook_Pipeline(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	requests := newRecorder()
+	seconds := &histogram{values: make(map[string][]float64)}
+	client.AddHook(NewMetricHook(
+		WithName("default"),
+		WithAddr("127.0.0.1:6379"),
+		WithRequests(requests),
+		WithSeconds(seconds),
+	))
+
+	ctx := context.Background()
+	_, _ = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, "a", "1", 0)
+		pipe.Set(ctx, "b", "2", 0)
+		pipe.Get(ctx, "missing")
+		pipe.Incr(ctx, "a")
+		pipe.HGet(ctx, "a", "field") // WRONGTYPE
+		return nil
+	})
+
+	want := map[string]float64{
+		"redis,default,127.0.0.1:6379,pipeline,Ok":         1,
+		"redis,default,127.0.0.1:6379,pipeline.set,Ok":     2,
+		"redis,default,127.0.0.1:6379,pipeline.get,Ok":     1,
+		"redis,default,127.0.0.1:6379,pipeline.incr,Ok":    1,
+		"redis,default,127.0.0.1:6379,pipeline.hget,Error": 1,
+	}
+	for k, v := range want {
+		if got := requests.values[k]; got != v {
+			t.Errorf("requests[%s] = %v, want %v", k, got, v)
+		}
+	}
+	if len(requests.values) != len(want) {
+		t.Errorf("requests = %v, want %v", requests.values, want)
+	}
+
+	// pipeline 的命令数默认记录在耗时直方图 command 为 pipeline.size 的序列上
+	sizes := seconds.values["redis,default,127.0.0.1:6379,pipeline.size"]
+	if len(sizes) != 1 || sizes[0] != 5 {
+		t.Errorf("pipeline sizes = %v, want [5]", sizes)
+	}
+	if n := len(seconds.values["redis,default,127.0.0.1:6379,pipeline"]); n != 1 {
+		t.Errorf("pipeline durations = %d, want 1", n)
+	}
+}
+
+func TestMetricHook_Process(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	requests := newRecorder()
+	client.AddHook(NewMetricHook(WithName("default"), WithRequests(requests), WithSeconds(observer{})))
+
+	ctx := context.Background()
+	_ = client.Get(ctx, "missing").Err()
+	_ = client.Set(ctx, "a", "1", 0).Err()
+	_ = client.HGet(ctx, "a", "field").Err()
+
+	want := map[string]float64{
+		"redis,default,,get,Ok":     1,
+		"redis,default,,set,Ok":     1,
+		"redis,default,,hget,Error": 1,
+	}
+	for k, v := range want {
+		if got := requests.values[k]; got != v {
+			t.Errorf("requests[%s] = %v, want %v", k, got, v)
+		}
+	}
+}
@@ -18,6 +18,8 @@ type options struct {
 	requests metrics.Counter
 	// histogram: db_client_requests_duration_ms_bucket{kind,addr,method}
 	seconds metrics.Observer
+	// histogram: pipeline 中的命令数，默认与 seconds 共用 command 为 pipeline.size 的序列
+	sizes metrics.Observer
 }
 
 // WithDisabled set disabled metrics.
@@ -54,3 +56,10 @@ func WithSeconds(c metrics.Observer) Option {
 		o.seconds = c
 	}
 }
+
+// WithSizes with pipeline sizes histogram.
+func WithSizes(c metrics.Observer) Option {
+	return func(o *options) {
+		o.sizes = c
+	}
+}
@@ -0,0 +1,271 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	prom "github.com/go-kratos/kratos/contrib/metrics/prometheus/v2"
+	"github.com/nextmicro/gokit/timex"
+	"github.com/nextmicro/logger"
+	"github.com/nextmicro/next/pkg/metrics"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const component = "redis"
+
+var (
+	// ErrNotObtained 锁已被其他持有者占用
+	ErrNotObtained = errors.New("redis lock: not obtained")
+	// ErrLockNotHeld 锁已过期或被其他持有者获取
+	ErrLockNotHeld = errors.New("redis lock: lock not held")
+)
+
+var (
+	releaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1])
+end
+return 0`)
+
+	refreshScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	ttlScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("pttl", KEYS[1])
+end
+return -3`)
+)
+
+// Client 基于 redis 的分布式锁
+type Client struct {
+	client redis.UniversalClient
+	opt    *options
+}
+
+// New 创建分布式锁客户端，client 可以是任意 redis.UniversalClient
+func New(client redis.UniversalClient, opts ...Option) *Client {
+	opt := &options{
+		requests: prom.NewCounter(metrics.DBSystemMetricRequests),
+		seconds:  prom.NewHistogram(metrics.DBSystemMetricMillisecond),
+	}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	return &Client{client: client, opt: opt}
+}
+
+// Obtain 获取锁，锁在 ttl 后自动过期，ttl 最小为1ms，获取失败时按重试策略重试，
+// 重试结束仍未获取时返回 ErrNotObtained，ctx 结束时返回同时包装 ErrNotObtained 与 ctx.Err() 的错误
+func (c *Client) Obtain(ctx context.Context, key string, ttl time.Duration, opts ...ObtainOption) (l *Lock, err error) {
+	// redis 过期时间的精度为毫秒
+	if ttl < time.Millisecond {
+		return nil, fmt.Errorf("redis lock: invalid ttl %s", ttl)
+	}
+
+	o := &obtainOptions{retry: NoRetry()}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	start := time.Now()
+	defer func() { c.observe(ctx, "lock.obtain", key, start, err) }()
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var timer *time.Timer
+	for retries := 0; ; retries++ {
+		ok, err := c.client.SetNX(ctx, key, token, ttl).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			break
+		}
+
+		backoff := o.retry.NextBackoff(retries)
+		if backoff <= 0 {
+			return nil, ErrNotObtained
+		}
+		if timer == nil {
+			timer = time.NewTimer(backoff)
+			defer timer.Stop()
+		} else {
+			timer.Reset(backoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", ErrNotObtained, ctx.Err())
+		case <-timer.C:
+		}
+	}
+
+	l = &Lock{client: c, key: key, token: token, ttl: ttl, done: make(chan struct{})}
+	if o.autoRefresh {
+		interval := o.refreshEvery
+		if interval <= 0 {
+			interval = ttl / 2
+		}
+		l.refreshing(interval)
+	}
+	return l, nil
+}
+
+func (c *Client) observe(ctx context.Context, command, key string, start time.Time, err error) {
+	duration := time.Since(start)
+	if !c.opt.disableMetric {
+		status := codes.Ok.String()
+		if err != nil {
+			status = codes.Error.String()
+		}
+		c.opt.requests.With(component, c.opt.name, c.opt.addr, command, status).Inc()
+		c.opt.seconds.With(component, c.opt.name, c.opt.addr, command).Observe(float64(duration.Milliseconds()))
+	}
+
+	if !c.opt.disableLogging {
+		fields := map[string]interface{}{
+			"kind":      "db",
+			"component": component,
+			"method":    command,
+			"key":       key,
+			"duration":  timex.Duration(duration),
+		}
+		log := logger.WithContext(ctx)
+		if err != nil {
+			fields["error"] = err
+		}
+		if err != nil && !errors.Is(err, ErrNotObtained) {
+			log.WithFields(fields).Error("[REDIS] Lock")
+		} else {
+			log.WithFields(fields).Info("[REDIS] Lock")
+		}
+	}
+}
+
+// Lock 已获取的锁
+type Lock struct {
+	client *Client
+	key    string
+	token  string
+	ttl    time.Duration
+
+	once   sync.Once
+	done   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Key 返回锁的 key
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Token 返回锁持有者的随机标识
+func (l *Lock) Token() string {
+	return l.token
+}
+
+// Done 在锁被释放或自动续期发现锁已丢失时关闭
+func (l *Lock) Done() <-chan struct{} {
+	return l.done
+}
+
+// TTL 返回锁的剩余时间，锁已丢失时返回 ErrLockNotHeld
+func (l *Lock) TTL(ctx context.Context) (time.Duration, error) {
+	res, err := ttlScript.Run(ctx, l.client.client, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return 0, err
+	}
+	if res < 0 {
+		return 0, ErrLockNotHeld
+	}
+	return time.Duration(res) * time.Millisecond, nil
+}
+
+// Refresh 将锁的过期时间延长为 ttl，锁已丢失时返回 ErrLockNotHeld
+func (l *Lock) Refresh(ctx context.Context, ttl time.Duration) (err error) {
+	start := time.Now()
+	defer func() { l.client.observe(ctx, "lock.refresh", l.key, start, err) }()
+
+	res, err := refreshScript.Run(ctx, l.client.client, []string{l.key}, l.token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Release 释放锁并停止自动续期，锁已丢失时返回 ErrLockNotHeld
+func (l *Lock) Release(ctx context.Context) (err error) {
+	if l.cancel != nil {
+		l.cancel()
+		l.wg.Wait()
+	}
+	defer l.close()
+
+	start := time.Now()
+	defer func() { l.client.observe(ctx, "lock.release", l.key, start, err) }()
+
+	res, err := releaseScript.Run(ctx, l.client.client, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// refreshing 后台按 interval 续期，锁丢失时关闭 done 并退出
+func (l *Lock) refreshing(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// 续期失败时在下个周期重试，直到锁确认丢失
+				err := l.Refresh(ctx, l.ttl)
+				if errors.Is(err, ErrLockNotHeld) {
+					l.close()
+					return
+				}
+			}
+		}
+	}()
+}
+
+func (l *Lock) close() {
+	l.once.Do(func() { close(l.done) })
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,213 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestClient(t *testing.T) (*Client, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return New(client, WithDisableMetric(true), WithDisableLogging(true)), mr
+}
+
+func TestClient_ObtainRelease(t *testing.T) {
+	c, mr := newTestClient(t)
+	ctx := context.Background()
+
+	l, err := c.Obtain(ctx, "lock", time.Minute)
+	if err != nil {
+		t.Fatalf("Obtain() error = %v", err)
+	}
+	if got, _ := mr.Get("lock"); got != l.Token() {
+		t.Fatalf("lock value = %q, want token %q", got, l.Token())
+	}
+
+	if _, err = c.Obtain(ctx, "lock", time.Minute); !errors.Is(err, ErrNotObtained) {
+		t.Fatalf("Obtain() held lock error = %v, want %v", err, ErrNotObtained)
+	}
+
+	if err = l.Release(ctx); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if mr.Exists("lock") {
+		t.Fatal("lock still exists after Release()")
+	}
+	select {
+	case <-l.Done():
+	default:
+		t.Fatal("Done() not closed after Release()")
+	}
+	if err = l.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("second Release() error = %v, want %v", err, ErrLockNotHeld)
+	}
+}
+
+func TestLock_ReleaseOtherHolder(t *testing.T) {
+	c, mr := newTestClient(t)
+	ctx := context.Background()
+
+	l, err := c.Obtain(ctx, "lock", time.Second)
+	if err != nil {
+		t.Fatalf("Obtain() error = %v", err)
+	}
+
+	// 锁过期后被其他持有者获取，释放与续期都不能影响新的持有者
+	mr.FastForward(2 * time.Second)
+	other, err := c.Obtain(ctx, "lock", time.Minute)
+	if err != nil {
+		t.Fatalf("Obtain() after expiry error = %v", err)
+	}
+
+	if err = l.Refresh(ctx, time.Minute); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("Refresh() error = %v, want %v", err, ErrLockNotHeld)
+	}
+	if _, err = l.TTL(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("TTL() error = %v, want %v", err, ErrLockNotHeld)
+	}
+	if err = l.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("Release() error = %v, want %v", err, ErrLockNotHeld)
+	}
+	if got, _ := mr.Get("lock"); got != other.Token() {
+		t.Fatalf("lock value = %q, want token %q", got, other.Token())
+	}
+}
+
+func TestLock_RefreshTTL(t *testing.T) {
+	c, mr := newTestClient(t)
+	ctx := context.Background()
+
+	l, err := c.Obtain(ctx, "lock", time.Second)
+	if err != nil {
+		t.Fatalf("Obtain() error = %v", err)
+	}
+	if err = l.Refresh(ctx, time.Minute); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if got := mr.TTL("lock"); got != time.Minute {
+		t.Fatalf("server ttl = %v, want %v", got, time.Minute)
+	}
+
+	ttl, err := l.TTL(ctx)
+	if err != nil {
+		t.Fatalf("TTL() error = %v", err)
+	}
+	if ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("TTL() = %v, want (0, %v]", ttl, time.Minute)
+	}
+}
+
+func TestClient_ObtainRetry(t *testing.T) {
+	c, mr := newTestClient(t)
+	ctx := context.Background()
+
+	if err := mr.Set("lock", "other"); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		mr.Del("lock")
+	}()
+
+	// 同一策略在多次 Obtain 间复用，重试次数互不影响
+	s := LimitRetry(LinearBackoff(10*time.Millisecond), 20)
+	l, err := c.Obtain(ctx, "lock", time.Minute, WithRetryStrategy(s))
+	if err != nil {
+		t.Fatalf("Obtain() with retry error = %v", err)
+	}
+	defer func() { _ = l.Release(ctx) }()
+
+	start := time.Now()
+	if _, err = c.Obtain(ctx, "lock", time.Minute, WithRetryStrategy(s)); !errors.Is(err, ErrNotObtained) {
+		t.Fatalf("Obtain() error = %v, want %v", err, ErrNotObtained)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("Obtain() gave up after %v, strategy state leaked between calls", elapsed)
+	}
+}
+
+func TestClient_ObtainContextDone(t *testing.T) {
+	c, mr := newTestClient(t)
+
+	if err := mr.Set("lock", "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	_, err := c.Obtain(ctx, "lock", time.Minute, WithRetryStrategy(LinearBackoff(10*time.Millisecond)))
+	if !errors.Is(err, ErrNotObtained) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Obtain() error = %v, want %v and %v", err, ErrNotObtained, context.DeadlineExceeded)
+	}
+}
+
+func TestLock_AutoRefresh(t *testing.T) {
+	c, mr := newTestClient(t)
+	ctx := context.Background()
+
+	l, err := c.Obtain(ctx, "lock", time.Second, WithAutoRefresh(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Obtain() error = %v", err)
+	}
+
+	// 自动续期将过期时间重置为 ttl
+	mr.SetTTL("lock", 100*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	if got := mr.TTL("lock"); got != time.Second {
+		t.Fatalf("server ttl = %v, want %v", got, time.Second)
+	}
+
+	// 锁丢失后续期失败并关闭 Done
+	mr.Del("lock")
+	select {
+	case <-l.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done() not closed after the lock was lost")
+	}
+	if err = l.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("Release() error = %v, want %v", err, ErrLockNotHeld)
+	}
+}
+
+func TestClient_ObtainInvalidTTL(t *testing.T) {
+	c, mr := newTestClient(t)
+
+	for _, ttl := range []time.Duration{-time.Second, 0, time.Nanosecond, time.Millisecond - 1} {
+		if _, err := c.Obtain(context.Background(), "lock", ttl, WithAutoRefresh(0)); err == nil || errors.Is(err, ErrNotObtained) {
+			t.Fatalf("Obtain(ttl %v) error = %v, want invalid ttl", ttl, err)
+		}
+	}
+	if mr.Exists("lock") {
+		t.Fatal("lock set with an invalid ttl")
+	}
+}
+
+func TestLock_AutoRefreshDefaultInterval(t *testing.T) {
+	c, mr := newTestClient(t)
+	ctx := context.Background()
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		l, err := c.Obtain(ctx, "lock", 40*time.Millisecond, WithAutoRefresh(interval))
+		if err != nil {
+			t.Fatalf("Obtain() error = %v", err)
+		}
+
+		// interval 小于等于0时每 ttl/2 续期
+		mr.SetTTL("lock", time.Millisecond)
+		time.Sleep(40 * time.Millisecond)
+		if got := mr.TTL("lock"); got != 40*time.Millisecond {
+			t.Fatalf("auto refresh %v server ttl = %v, want %v", interval, got, 40*time.Millisecond)
+		}
+		if err = l.Release(ctx); err != nil {
+			t.Fatalf("Release() error = %v", err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package lock
+
+import (
+	"time"
+
+	"github.com/go-kratos/kratos/v2/metrics"
+)
+
+// Option is lock client option.
+type Option func(o *options)
+
+type options struct {
+	// redis name.
+	name string
+	// redis address.
+	addr string
+	// disabled metrics.
+	disableMetric bool
+	// disabled logging.
+	disableLogging bool
+	// counter: db_client_requests_total{kind,name,addr,command,status}
+	requests metrics.Counter
+	// histogram: db_client_requests_duration_ms_bucket{kind,name,addr,command}
+	seconds metrics.Observer
+}
+
+// WithName with name label.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithAddr with addr label.
+func WithAddr(addr string) Option {
+	return func(o *options) {
+		o.addr = addr
+	}
+}
+
+// WithDisableMetric set disabled metrics.
+func WithDisableMetric(disabled bool) Option {
+	return func(o *options) {
+		o.disableMetric = disabled
+	}
+}
+
+// WithDisableLogging set disabled logging.
+func WithDisableLogging(disabled bool) Option {
+	return func(o *options) {
+		o.disableLogging = disabled
+	}
+}
+
+// WithRequests with requests counter.
+func WithRequests(c metrics.Counter) Option {
+	return func(o *options) {
+		o.requests = c
+	}
+}
+
+// WithSeconds with seconds histogram.
+func WithSeconds(c metrics.Observer) Option {
+	return func(o *options) {
+		o.seconds = c
+	}
+}
+
+// ObtainOption is Obtain option.
+type ObtainOption func(o *obtainOptions)
+
+type obtainOptions struct {
+	retry        RetryStrategy
+	autoRefresh  bool
+	refreshEvery time.Duration
+}
+
+// WithRetryStrategy 设置获取锁失败后的重试策略，默认不重试
+func WithRetryStrategy(s RetryStrategy) ObtainOption {
+	return func(o *obtainOptions) {
+		o.retry = s
+	}
+}
+
+// WithAutoRefresh 开启自动续期，每隔 interval 将锁的过期时间延长为 ttl，
+// interval 小于等于0时为 ttl/2，直到 Release 或锁丢失
+func WithAutoRefresh(interval time.Duration) ObtainOption {
+	return func(o *obtainOptions) {
+		o.autoRefresh = true
+		o.refreshEvery = interval
+	}
+}
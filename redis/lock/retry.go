@@ -0,0 +1,65 @@
+package lock
+
+import "time"
+
+// RetryStrategy 获取锁失败后的重试策略，NextBackoff 返回第 retries+1 次重试前的等待时间，
+// 返回0时不再重试。策略不保存状态，重试次数由每次 Obtain 各自记录，同一实例可在多次 Obtain 间复用
+type RetryStrategy interface {
+	NextBackoff(retries int) time.Duration
+}
+
+type noRetry struct{}
+
+// NoRetry 不重试，获取失败立即返回 ErrNotObtained
+func NoRetry() RetryStrategy {
+	return noRetry{}
+}
+
+func (noRetry) NextBackoff(int) time.Duration {
+	return 0
+}
+
+type linearBackoff time.Duration
+
+// LinearBackoff 按固定间隔重试，直到 ctx 结束
+func LinearBackoff(backoff time.Duration) RetryStrategy {
+	return linearBackoff(backoff)
+}
+
+func (r linearBackoff) NextBackoff(int) time.Duration {
+	return time.Duration(r)
+}
+
+type exponentialBackoff struct {
+	min, max time.Duration
+}
+
+// ExponentialBackoff 按指数退避重试，间隔从 min 开始每次翻倍，不超过 max，直到 ctx 结束
+func ExponentialBackoff(min, max time.Duration) RetryStrategy {
+	return exponentialBackoff{min: min, max: max}
+}
+
+func (r exponentialBackoff) NextBackoff(retries int) time.Duration {
+	backoff := r.min
+	for i := 0; i < retries && backoff < r.max; i++ {
+		backoff *= 2
+	}
+	return min(backoff, r.max)
+}
+
+type limitedRetry struct {
+	s   RetryStrategy
+	max int
+}
+
+// LimitRetry 限制策略 s 的最大重试次数
+func LimitRetry(s RetryStrategy, max int) RetryStrategy {
+	return limitedRetry{s: s, max: max}
+}
+
+func (r limitedRetry) NextBackoff(retries int) time.Duration {
+	if retries >= r.max {
+		return 0
+	}
+	return r.s.NextBackoff(retries)
+}
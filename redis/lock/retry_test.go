@@ -0,0 +1,43 @@
+package lock
+
+import (
+	"testing"
+	"time"
+)
+
+func backoffs(s RetryStrategy, n int) []time.Duration {
+	out := make([]time.Duration, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, s.NextBackoff(i))
+	}
+	return out
+}
+
+func TestRetryStrategy(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name string
+		s    RetryStrategy
+		want []time.Duration
+	}{
+		{"no retry", NoRetry(), []time.Duration{0, 0}},
+		{"linear", LinearBackoff(10 * ms), []time.Duration{10 * ms, 10 * ms, 10 * ms}},
+		{"exponential", ExponentialBackoff(10*ms, 50*ms), []time.Duration{10 * ms, 20 * ms, 40 * ms, 50 * ms, 50 * ms}},
+		{"exponential min over max", ExponentialBackoff(80*ms, 50*ms), []time.Duration{50 * ms, 50 * ms}},
+		{"limit", LimitRetry(LinearBackoff(10*ms), 2), []time.Duration{10 * ms, 10 * ms, 0, 0}},
+		{"limit exponential", LimitRetry(ExponentialBackoff(10*ms, time.Second), 3), []time.Duration{10 * ms, 20 * ms, 40 * ms, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 策略无状态，重复计算结果一致
+			for round := 0; round < 2; round++ {
+				got := backoffs(tt.s, len(tt.want))
+				for i := range tt.want {
+					if got[i] != tt.want[i] {
+						t.Fatalf("round %d: NextBackoff() = %v, want %v", round, got, tt.want)
+					}
+				}
+			}
+		})
+	}
+}
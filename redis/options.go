@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/nextmicro/next-component/internal/tlsconfig"
+	"github.com/nextmicro/next-component/redis/hook/logging"
 	"github.com/nextmicro/next/runtime/loader"
 	redis "github.com/redis/go-redis/v9"
 )
@@ -19,24 +21,36 @@ func (fn OptionFunc) apply(cfg *Options) {
 }
 
 type Options struct {
-	Addrs           []string      `json:"addrs"`             // 单个地址或者集群地址
-	ClientName      string        `json:"client_name"`       // ClientName 将为每个 conn 执行 `CLIENT SETNAME ClientName` 命令
-	Username        string        `json:"username"`          // 用户名
-	Password        string        `json:"password"`          // Password 密码
-	DB              int           `json:"db"`                // DB，默认为0, 一般应用不推荐使用DB分片
-	PoolFIFO        bool          `json:"pool_fifo"`         // 每个节点连接池GETPUT使用先进先出模式（默认LIFO）
-	PoolSize        int           `json:"pool_size"`         // PoolSize 集群内每个节点的最大连接池限制 默认每个CPU10个连接
-	PoolTimeout     time.Duration `json:"pool_timeout"`      // 连接池超时时间
-	MaxRetries      int           `json:"max_retries"`       // MaxRetries 网络相关的错误最大重试次数 默认5次
-	MinRetryBackoff time.Duration `json:"min_retry_backoff"` // 网络相关的错误最小重试时间
-	MaxRetryBackoff time.Duration `json:"max_retry_backoff"` // 网络相关的错误最大重试时间
-	MinIdleConns    int           `json:"min_idle_conns"`    // MinIdleConns 最小空闲连接数 默认20个
-	MaxIdleConns    int           `json:"max_idle_conns"`    // 最大空闲连接数
-	DialTimeout     time.Duration `json:"dial_timeout"`      // DialTimeout 拨超时时间
-	ReadTimeout     time.Duration `json:"read_timeout"`      // ReadTimeout 读超时
-	WriteTimeout    time.Duration `json:"write_timeout"`     // WriteTimeout 写超时
-	IdleTimeout     time.Duration `json:"idle_timeout"`      // IdleTimeout 连接最大空闲时间，默认60s, 超过该时间，连接会被主动关闭
-	SlowThreshold   time.Duration `json:"slow_threshold"`    // 慢日志门限值，超过该门限值的请求，将被记录到慢日志中
+	Addrs           []string      `json:"addrs"`              // 单个地址或者集群地址
+	ClientName      string        `json:"client_name"`        // ClientName 将为每个 conn 执行 `CLIENT SETNAME ClientName` 命令
+	Username        string        `json:"username"`           // 用户名
+	Password        string        `json:"password"`           // Password 密码
+	DB              int           `json:"db"`                 // DB，默认为0, 一般应用不推荐使用DB分片
+	PoolFIFO        bool          `json:"pool_fifo"`          // 每个节点连接池GETPUT使用先进先出模式（默认LIFO）
+	PoolSize        int           `json:"pool_size"`          // PoolSize 集群内每个节点的最大连接池限制 默认每个CPU10个连接
+	PoolTimeout     time.Duration `json:"pool_timeout"`       // 连接池超时时间
+	MaxRetries      int           `json:"max_retries"`        // MaxRetries 网络相关的错误最大重试次数 默认5次
+	MinRetryBackoff time.Duration `json:"min_retry_backoff"`  // 网络相关的错误最小重试时间
+	MaxRetryBackoff time.Duration `json:"max_retry_backoff"`  // 网络相关的错误最大重试时间
+	MinIdleConns    int           `json:"min_idle_conns"`     // MinIdleConns 最小空闲连接数 默认20个
+	MaxIdleConns    int           `json:"max_idle_conns"`     // 最大空闲连接数
+	DialTimeout     time.Duration `json:"dial_timeout"`       // DialTimeout 拨超时时间
+	ReadTimeout     time.Duration `json:"read_timeout"`       // ReadTimeout 读超时
+	WriteTimeout    time.Duration `json:"write_timeout"`      // WriteTimeout 写超时
+	IdleTimeout     time.Duration `json:"idle_timeout"`       // IdleTimeout 已废弃，等同于 conn_max_idle_time，两者同时配置时以 conn_max_idle_time 为准
+	ConnMaxIdleTime time.Duration `json:"conn_max_idle_time"` // 连接最大空闲时间，默认30m, 超过该时间，连接会被主动关闭
+	ConnMaxLifetime time.Duration `json:"conn_max_lifetime"`  // 连接最大存活时间，默认不限制
+	TLS             *TLS          `json:"tls"`                // TLS 配置，为空时不启用
+	SlowThreshold   time.Duration `json:"slow_threshold"`     // 慢日志门限值，超过该门限值的请求，将被记录到慢日志中
+	GracePeriod     time.Duration `json:"grace_period"`       // 热更新后旧连接关闭前的等待时间，默认30s
+	Lazy            bool          `json:"lazy"`               // 延迟连接，首次获取实例时才建立连接
+	Optional        bool          `json:"optional"`           // 非关键实例，启动时连接失败仅记录日志，不影响组件初始化
+
+	ConnectMaxAttempts int           `json:"connect_max_attempts"` // 建立连接的最大尝试次数，默认1次即不重试
+	ConnectMinBackoff  time.Duration `json:"connect_min_backoff"`  // 建立连接重试的初始间隔，默认1s，每次翻倍
+	ConnectMaxBackoff  time.Duration `json:"connect_max_backoff"`  // 建立连接重试的最大间隔，默认10s
+	ConnectTimeout     time.Duration `json:"connect_timeout"`      // 建立连接（含重试）的总时长，默认不限制
+
 	// Only cluster clients.
 	ReadOnly       bool `json:"read_only"`        // 在从节点上启用只读命令
 	RouteByLatency bool `json:"route_by_latency"` // 允许将只读命令路由到最近的主节点或从节点。它会自动启用只读
@@ -45,7 +59,11 @@ type Options struct {
 	// The sentinel master name.
 	// Only failover clients.
 
-	MasterName string `json:"master_name"` // 主节点名称
+	MasterName       string `json:"master_name"`       // 主节点名称
+	SentinelUsername string `json:"sentinel_username"` // 哨兵用户名，为空时使用 username
+	SentinelPassword string `json:"sentinel_password"` // 哨兵密码，为空时使用 password
+
+	Streams []Stream `json:"streams"` // 消费的 stream，通过 HandleStream 注册处理函数后在 Start 时开始消费
 
 	DisableMetric         bool         `json:"disable_metric"`          // 禁用监控，默认开启
 	DisableTrace          bool         `json:"disable_trace"`           // 禁用链路，默认开启
@@ -53,11 +71,25 @@ type Options struct {
 	EnableLoggingRequest  bool         `json:"enable_logging_request"`  // 是否开启记录请求参数
 	EnableLoggingResponse bool         `json:"enable_logging_response"` // 是否开启记录响应参数
 	Hooks                 []redis.Hook `json:"-"`                       // redis钩子
+
+	LoggingRedactRules    []logging.Rule   `json:"logging_redact_rules"`     // 日志脱敏规则，AUTH、HELLO 的参数始终隐藏
+	LoggingMaxArgLen      int              `json:"logging_max_arg_len"`      // 日志中单个参数的最大长度，超出部分截断，默认不限制
+	LoggingMaxResponseLen int              `json:"logging_max_response_len"` // 日志中响应的最大长度，超出部分截断，默认不限制
+	LoggingRedactor       logging.Redactor `json:"-"`                        // 自定义脱敏实现，设置后忽略脱敏规则与长度限制
+
+	// EnableEnvelope 是否在 Publish 的消息前写入信封以携带链路信息，默认关闭，消息与原始内容一致。
+	// 开启后消息格式变为 0x00 'N' 'X' 'T' | headers 长度(uint32, 大端) | headers(JSON) | body，
+	// 本组件的订阅会自动解包，其他订阅方需先升级为本组件或自行解包后再开启
+	EnableEnvelope bool `json:"enable_envelope"`
 }
 
+// TLS 连接 redis 的 TLS 配置
+type TLS = tlsconfig.Config
+
 const (
-	namespace   = "go-redis"
-	defaultName = "default"
+	namespace          = "go-redis"
+	defaultName        = "default"
+	defaultGracePeriod = 30 * time.Second
 )
 
 type redisConfig struct{}
@@ -78,21 +110,39 @@ func (o *Options) Options() []Option {
 	if len(o.Addrs) > 0 {
 		opts = append(opts, WithAddress(o.Addrs))
 	}
+	if o.ClientName != "" {
+		opts = append(opts, WithClientName(o.ClientName))
+	}
+	if o.Username != "" {
+		opts = append(opts, WithUsername(o.Username))
+	}
 	if o.Password != "" {
 		opts = append(opts, WithPassword(o.Password))
 	}
 	if o.DB != 0 {
 		opts = append(opts, WithDB(o.DB))
 	}
+	if o.PoolFIFO {
+		opts = append(opts, WithPoolFIFO())
+	}
 	if o.PoolSize != 0 {
 		opts = append(opts, WithPoolSize(o.PoolSize))
 	}
+	if o.PoolTimeout != 0 {
+		opts = append(opts, WithPoolTimeout(o.PoolTimeout))
+	}
 	if o.MaxRetries != 0 {
 		opts = append(opts, WithMaxRetries(o.MaxRetries))
 	}
+	if o.MinRetryBackoff != 0 || o.MaxRetryBackoff != 0 {
+		opts = append(opts, WithRetryBackoff(o.MinRetryBackoff, o.MaxRetryBackoff))
+	}
 	if o.MinIdleConns != 0 {
 		opts = append(opts, WithMinIdleConns(o.MinIdleConns))
 	}
+	if o.MaxIdleConns != 0 {
+		opts = append(opts, WithMaxIdleConns(o.MaxIdleConns))
+	}
 	if o.DialTimeout != 0 {
 		opts = append(opts, WithDialTimeout(o.DialTimeout))
 	}
@@ -105,9 +155,51 @@ func (o *Options) Options() []Option {
 	if o.IdleTimeout != 0 {
 		opts = append(opts, WithIdleTimeout(o.IdleTimeout))
 	}
+	if o.ConnMaxIdleTime != 0 {
+		opts = append(opts, WithConnMaxIdleTime(o.ConnMaxIdleTime))
+	}
+	if o.ConnMaxLifetime != 0 {
+		opts = append(opts, WithConnMaxLifetime(o.ConnMaxLifetime))
+	}
+	if o.TLS != nil {
+		opts = append(opts, WithTLS(*o.TLS))
+	}
 	if o.SlowThreshold != 0 {
 		opts = append(opts, WithSlowThreshold(o.SlowThreshold))
 	}
+	if o.GracePeriod != 0 {
+		opts = append(opts, WithGracePeriod(o.GracePeriod))
+	}
+	if len(o.Streams) > 0 {
+		opts = append(opts, WithStreams(o.Streams...))
+	}
+	if o.Lazy {
+		opts = append(opts, WithLazy())
+	}
+	if o.Optional {
+		opts = append(opts, WithOptional())
+	}
+	if o.ConnectMaxAttempts != 0 || o.ConnectMinBackoff != 0 || o.ConnectMaxBackoff != 0 {
+		opts = append(opts, WithConnectRetry(o.ConnectMaxAttempts, o.ConnectMinBackoff, o.ConnectMaxBackoff))
+	}
+	if o.ConnectTimeout != 0 {
+		opts = append(opts, WithConnectTimeout(o.ConnectTimeout))
+	}
+	if o.ReadOnly {
+		opts = append(opts, WithReadOnly())
+	}
+	if o.RouteByLatency {
+		opts = append(opts, WithRouteByLatency())
+	}
+	if o.RouteRandomly {
+		opts = append(opts, WithRouteRandomly())
+	}
+	if o.MasterName != "" {
+		opts = append(opts, WithMasterName(o.MasterName))
+	}
+	if o.SentinelUsername != "" || o.SentinelPassword != "" {
+		opts = append(opts, WithSentinel(o.SentinelUsername, o.SentinelPassword))
+	}
 	if o.DisableMetric {
 		opts = append(opts, WithDisableMetric())
 	}
@@ -117,6 +209,9 @@ func (o *Options) Options() []Option {
 	if o.DisableLogging {
 		opts = append(opts, WithDisableLogging())
 	}
+	if o.EnableEnvelope {
+		opts = append(opts, WithEnableEnvelope())
+	}
 	if o.EnableLoggingRequest {
 		opts = append(opts, WithEnableLoggingRequest())
 	}
@@ -126,6 +221,15 @@ func (o *Options) Options() []Option {
 	if len(o.Hooks) > 0 {
 		opts = append(opts, WithHook(o.Hooks...))
 	}
+	if len(o.LoggingRedactRules) > 0 {
+		opts = append(opts, WithLoggingRedactRules(o.LoggingRedactRules...))
+	}
+	if o.LoggingMaxArgLen != 0 || o.LoggingMaxResponseLen != 0 {
+		opts = append(opts, WithLoggingMaxLen(o.LoggingMaxArgLen, o.LoggingMaxResponseLen))
+	}
+	if o.LoggingRedactor != nil {
+		opts = append(opts, WithLoggingRedactor(o.LoggingRedactor))
+	}
 	return opts
 }
 
@@ -136,6 +240,20 @@ func WithAddress(addrs []string) Option {
 	})
 }
 
+// WithClientName 设置连接名称
+func WithClientName(clientName string) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.ClientName = clientName
+	})
+}
+
+// WithUsername 设置用户名
+func WithUsername(username string) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Username = username
+	})
+}
+
 // WithPassword 设置密码
 func WithPassword(password string) Option {
 	return OptionFunc(func(cfg *Options) {
@@ -157,6 +275,20 @@ func WithPoolSize(poolSize int) Option {
 	})
 }
 
+// WithPoolFIFO 设置连接池使用先进先出模式
+func WithPoolFIFO() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.PoolFIFO = true
+	})
+}
+
+// WithPoolTimeout 设置连接池超时时间
+func WithPoolTimeout(poolTimeout time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.PoolTimeout = poolTimeout
+	})
+}
+
 // WithMaxRetries 设置最大重试次数
 func WithMaxRetries(maxRetries int) Option {
 	return OptionFunc(func(cfg *Options) {
@@ -164,6 +296,14 @@ func WithMaxRetries(maxRetries int) Option {
 	})
 }
 
+// WithRetryBackoff 设置网络相关的错误重试的最小、最大间隔
+func WithRetryBackoff(minBackoff, maxBackoff time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.MinRetryBackoff = minBackoff
+		cfg.MaxRetryBackoff = maxBackoff
+	})
+}
+
 // WithMinIdleConns 设置最小空闲连接数
 func WithMinIdleConns(minIdleConns int) Option {
 	return OptionFunc(func(cfg *Options) {
@@ -171,6 +311,13 @@ func WithMinIdleConns(minIdleConns int) Option {
 	})
 }
 
+// WithMaxIdleConns 设置最大空闲连接数
+func WithMaxIdleConns(maxIdleConns int) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.MaxIdleConns = maxIdleConns
+	})
+}
+
 // WithDialTimeout 设置拨号超时时间
 func WithDialTimeout(dialTimeout time.Duration) Option {
 	return OptionFunc(func(cfg *Options) {
@@ -193,12 +340,35 @@ func WithWriteTimeout(writeTimeout time.Duration) Option {
 }
 
 // WithIdleTimeout 设置连接最大空闲时间
+//
+// Deprecated: 使用 WithConnMaxIdleTime
 func WithIdleTimeout(idleTimeout time.Duration) Option {
 	return OptionFunc(func(cfg *Options) {
 		cfg.IdleTimeout = idleTimeout
 	})
 }
 
+// WithConnMaxIdleTime 设置连接最大空闲时间
+func WithConnMaxIdleTime(d time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.ConnMaxIdleTime = d
+	})
+}
+
+// WithConnMaxLifetime 设置连接最大存活时间
+func WithConnMaxLifetime(d time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.ConnMaxLifetime = d
+	})
+}
+
+// WithTLS 设置 TLS 配置
+func WithTLS(tls TLS) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.TLS = &tls
+	})
+}
+
 // WithSlowThreshold 设置慢日志门限值
 func WithSlowThreshold(slowThreshold time.Duration) Option {
 	return OptionFunc(func(cfg *Options) {
@@ -206,6 +376,86 @@ func WithSlowThreshold(slowThreshold time.Duration) Option {
 	})
 }
 
+// WithGracePeriod 设置热更新后旧连接关闭前的等待时间
+func WithGracePeriod(gracePeriod time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.GracePeriod = gracePeriod
+	})
+}
+
+// WithLazy 设置延迟连接
+func WithLazy() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Lazy = true
+	})
+}
+
+// WithStreams 设置消费的 stream
+func WithStreams(streams ...Stream) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Streams = streams
+	})
+}
+
+// WithOptional 设置为非关键实例
+func WithOptional() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.Optional = true
+	})
+}
+
+// WithConnectRetry 设置建立连接的最大尝试次数与重试间隔
+func WithConnectRetry(maxAttempts int, minBackoff, maxBackoff time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.ConnectMaxAttempts = maxAttempts
+		cfg.ConnectMinBackoff = minBackoff
+		cfg.ConnectMaxBackoff = maxBackoff
+	})
+}
+
+// WithConnectTimeout 设置建立连接（含重试）的总时长
+func WithConnectTimeout(timeout time.Duration) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.ConnectTimeout = timeout
+	})
+}
+
+// WithReadOnly 设置在从节点上启用只读命令，仅集群模式有效
+func WithReadOnly() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.ReadOnly = true
+	})
+}
+
+// WithRouteByLatency 设置只读命令路由到延迟最低的节点，仅集群模式有效
+func WithRouteByLatency() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.RouteByLatency = true
+	})
+}
+
+// WithRouteRandomly 设置只读命令路由到随机节点，仅集群模式有效
+func WithRouteRandomly() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.RouteRandomly = true
+	})
+}
+
+// WithMasterName 设置哨兵主节点名称，设置后以哨兵模式连接
+func WithMasterName(masterName string) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.MasterName = masterName
+	})
+}
+
+// WithSentinel 设置哨兵的用户名与密码
+func WithSentinel(username, password string) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.SentinelUsername = username
+		cfg.SentinelPassword = password
+	})
+}
+
 // WithDisableMetric 设置禁用监控
 func WithDisableMetric() Option {
 	return OptionFunc(func(cfg *Options) {
@@ -227,6 +477,13 @@ func WithDisableLogging() Option {
 	})
 }
 
+// WithEnableEnvelope 设置在 Publish 的消息前写入信封以携带链路信息，会改变消息格式
+func WithEnableEnvelope() Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.EnableEnvelope = true
+	})
+}
+
 // WithEnableLoggingRequest 设置开启记录请求参数
 func WithEnableLoggingRequest() Option {
 	return OptionFunc(func(cfg *Options) {
@@ -247,3 +504,25 @@ func WithHook(hook ...redis.Hook) Option {
 		cfg.Hooks = append(cfg.Hooks, hook...)
 	})
 }
+
+// WithLoggingRedactRules 设置日志脱敏规则
+func WithLoggingRedactRules(rules ...logging.Rule) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.LoggingRedactRules = rules
+	})
+}
+
+// WithLoggingMaxLen 设置日志中单个参数与响应的最大长度
+func WithLoggingMaxLen(maxArgLen, maxResponseLen int) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.LoggingMaxArgLen = maxArgLen
+		cfg.LoggingMaxResponseLen = maxResponseLen
+	})
+}
+
+// WithLoggingRedactor 设置自定义日志脱敏实现
+func WithLoggingRedactor(r logging.Redactor) Option {
+	return OptionFunc(func(cfg *Options) {
+		cfg.LoggingRedactor = r
+	})
+}
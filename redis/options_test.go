@@ -0,0 +1,155 @@
+package redis
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	kconfig "github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+	"github.com/nextmicro/next-component/redis/hook/logging"
+	"github.com/nextmicro/next/config"
+	"github.com/redis/go-redis/v9"
+)
+
+func loadConfig(t *testing.T, content string) {
+	filename := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := kconfig.New(kconfig.WithSource(file.NewSource(filename)))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	config.DefaultConfig = c
+	t.Cleanup(func() { _ = c.Close() })
+}
+
+type nopHook struct{}
+
+func (nopHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (nopHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook { return next }
+
+func (nopHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func fullOptions() Options {
+	return Options{
+		Addrs:              []string{"127.0.0.1:6379", "127.0.0.1:6380"},
+		ClientName:         "test",
+		Username:           "user",
+		Password:           "password",
+		DB:                 1,
+		PoolFIFO:           true,
+		PoolSize:           8,
+		PoolTimeout:        time.Second,
+		MaxRetries:         2,
+		MinRetryBackoff:    time.Millisecond,
+		MaxRetryBackoff:    time.Second,
+		MinIdleConns:       2,
+		MaxIdleConns:       4,
+		DialTimeout:        time.Second,
+		ReadTimeout:        time.Second,
+		WriteTimeout:       time.Second,
+		IdleTimeout:        time.Minute,
+		ConnMaxIdleTime:    2 * time.Minute,
+		ConnMaxLifetime:    time.Hour,
+		TLS:                &TLS{ServerName: "redis", InsecureSkipVerify: true},
+		SlowThreshold:      time.Second,
+		GracePeriod:        time.Second,
+		Lazy:               true,
+		Optional:           true,
+		ConnectMaxAttempts: 3,
+		ConnectMinBackoff:  time.Millisecond,
+		ConnectMaxBackoff:  time.Second,
+		ConnectTimeout:     time.Minute,
+		ReadOnly:           true,
+		RouteByLatency:     true,
+		RouteRandomly:      true,
+		MasterName:         "master",
+		SentinelUsername:   "sentinel",
+		SentinelPassword:   "sentinel-password",
+		Streams:            []Stream{{Stream: "orders", Group: "billing"}},
+		DisableMetric:      true,
+		DisableTrace:       true,
+		DisableLogging:     true,
+		EnableEnvelope:     true,
+
+		EnableLoggingRequest:  true,
+		EnableLoggingResponse: true,
+		Hooks:                 []redis.Hook{nopHook{}},
+		LoggingRedactRules:    []logging.Rule{{Command: "SET", Keys: []string{"user:*"}, Values: true}},
+		LoggingMaxArgLen:      64,
+		LoggingMaxResponseLen: 128,
+		LoggingRedactor:       logging.NewRedaction(nil, 1, 2),
+	}
+}
+
+func TestOptions_Options(t *testing.T) {
+	tests := []Options{
+		fullOptions(),
+		// 只设置其中一项时同样生效
+		{IdleTimeout: time.Minute},
+		{SentinelPassword: "sentinel-password"},
+		{MaxRetryBackoff: time.Second},
+		{ConnectMaxBackoff: time.Second},
+		{LoggingMaxResponseLen: 128},
+	}
+	for _, want := range tests {
+		var got Options
+		for _, opt := range want.Options() {
+			opt.apply(&got)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Options() applied = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestComponent_InitWithConfig(t *testing.T) {
+	loadConfig(t, `{"go-redis":{"default":{"addrs":["127.0.0.1:1"]}}}`)
+
+	want := fullOptions()
+	c := New()
+	if err := c.Init(WithConfig(want)); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if got := c.opts[defaultName]; !reflect.DeepEqual(*got, want) {
+		t.Fatalf("Init(WithConfig()) options = %+v, want %+v", *got, want)
+	}
+}
+
+func TestComponent_ConnectDefaults(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := New()
+
+	opt := &Options{
+		Addrs:          []string{mr.Addr()},
+		Username:       "user",
+		Password:       "password",
+		IdleTimeout:    time.Minute,
+		DisableMetric:  true,
+		DisableTrace:   true,
+		DisableLogging: true,
+	}
+	mr.RequireUserAuth("user", "password")
+	client, err := c.connect(defaultName, opt)
+	if err != nil {
+		t.Fatalf("connect() error = %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	// 未配置 ConnMaxIdleTime 时沿用已废弃的 IdleTimeout
+	if got := client.(*redis.Client).Options().ConnMaxIdleTime; got != time.Minute {
+		t.Fatalf("ConnMaxIdleTime = %v, want %v", got, time.Minute)
+	}
+	if opt.ConnMaxIdleTime != 0 {
+		t.Fatal("connect() modified the instance options")
+	}
+}
@@ -0,0 +1,354 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nextmicro/gokit/timex"
+	"github.com/nextmicro/logger"
+	"github.com/nextmicro/next-component/internal/envelope"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	kind       = "redis"
+	tracerName = "github.com/nextmicro/next-component/redis"
+
+	// swapCheckInterval 检查实例是否因热更新被替换的间隔
+	swapCheckInterval = time.Second
+	// resubscribeBackoff 重新订阅失败后的等待时间
+	resubscribeBackoff = time.Second
+)
+
+var tracer = otel.Tracer(tracerName)
+
+// PubSubMessage 订阅到的消息
+type PubSubMessage struct {
+	Channel string            // 频道
+	Pattern string            // 匹配的模式，仅模式订阅时有值
+	Payload []byte            // 消息体
+	Header  map[string]string // 消息信封中的 header
+}
+
+// PubSubHandler 订阅消息处理函数，pub/sub 不支持重投递，返回的 error 仅记录监控与日志
+type PubSubHandler func(ctx context.Context, msg *PubSubMessage) error
+
+// SubscribeOption 订阅选项
+type SubscribeOption func(o *subscribeOptions)
+
+type subscribeOptions struct {
+	patterns    bool
+	concurrency int
+}
+
+// WithPatterns 以 PSUBSCRIBE 订阅，channels 作为模式匹配
+func WithPatterns() SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.patterns = true
+	}
+}
+
+// WithSubscribeConcurrency 设置并发处理的协程数，默认1即按顺序处理
+func WithSubscribeConcurrency(n int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.concurrency = n
+	}
+}
+
+// Subscription 由组件管理的订阅，连接断开时自动重连并重新订阅，
+// 实例热更新后自动在新连接上重新订阅
+type Subscription struct {
+	c        *Component
+	name     string
+	channels []string
+	handler  PubSubHandler
+	opt      subscribeOptions
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	client redis.UniversalClient
+	pubsub *redis.PubSub
+	sem    chan struct{}
+	wg     sync.WaitGroup
+}
+
+// Publish 向命名实例的频道发布消息，开启信封时将链路信息写入消息信封，默认发布原始消息
+func (c *Component) Publish(ctx context.Context, name, channel string, payload []byte) (err error) {
+	if name == "" {
+		name = defaultName
+	}
+
+	client, err := c.Get(name)
+	if err != nil {
+		return err
+	}
+	opt, ok := c.config(name)
+	if !ok {
+		return fmt.Errorf("redis: %w, group: %s", ErrInstanceNotFound, name)
+	}
+
+	if !opt.DisableTrace {
+		var span trace.Span
+		ctx, span = tracer.Start(ctx, channel+" publish",
+			trace.WithSpanKind(trace.SpanKindProducer),
+			trace.WithAttributes(
+				semconv.MessagingSystemKey.String(kind),
+				semconv.MessagingDestinationName(channel),
+				semconv.MessagingOperationPublish,
+			),
+		)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+	if !opt.EnableEnvelope {
+		return client.Publish(ctx, channel, payload).Err()
+	}
+
+	headers := propagation.MapCarrier{}
+	if !opt.DisableTrace {
+		otel.GetTextMapPropagator().Inject(ctx, headers)
+	}
+	data, err := envelope.Encode(headers, payload)
+	if err != nil {
+		return err
+	}
+	return client.Publish(ctx, channel, data).Err()
+}
+
+// Subscribe 在命名实例上订阅频道，首次订阅成功后返回，之后由后台协程接收消息并调用 handler。
+// ctx 结束、调用 Subscription.Close 或组件 Stop 时取消订阅并等待处理中的消息完成
+func (c *Component) Subscribe(ctx context.Context, name string, channels []string, handler PubSubHandler, opts ...SubscribeOption) (*Subscription, error) {
+	if name == "" {
+		name = defaultName
+	}
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("redis: subscribe %s without channels", name)
+	}
+
+	o := subscribeOptions{concurrency: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.concurrency <= 0 {
+		o.concurrency = 1
+	}
+
+	client, err := c.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Subscription{
+		c:        c,
+		name:     name,
+		channels: channels,
+		handler:  handler,
+		opt:      o,
+		sem:      make(chan struct{}, o.concurrency),
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	if err = s.subscribe(client); err != nil {
+		s.cancel()
+		return nil, err
+	}
+
+	c.subs.Store(s, struct{}{})
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// Close 取消订阅并等待处理中的消息完成
+func (s *Subscription) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	s.c.subs.Delete(s)
+	return nil
+}
+
+// subscribe 在 client 上订阅并等待服务端确认
+func (s *Subscription) subscribe(client redis.UniversalClient) error {
+	var pubsub *redis.PubSub
+	if s.opt.patterns {
+		pubsub = client.PSubscribe(s.ctx, s.channels...)
+	} else {
+		pubsub = client.Subscribe(s.ctx, s.channels...)
+	}
+	if _, err := pubsub.Receive(s.ctx); err != nil {
+		_ = pubsub.Close()
+		return fmt.Errorf("redis: subscribe %s %v %w", s.name, s.channels, err)
+	}
+
+	s.client, s.pubsub = client, pubsub
+	return nil
+}
+
+// run 接收消息直到订阅结束。网络错误由 PubSub 自动重连并重新订阅；
+// 连接被关闭或实例被热更新替换时，在最新的实例上重新订阅
+func (s *Subscription) run() {
+	defer s.wg.Done()
+	defer func() { _ = s.pubsub.Close() }()
+
+	for {
+		s.receive()
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		for {
+			client, err := s.c.Get(s.name)
+			if err == nil {
+				// 先在新连接上订阅再关闭旧订阅，切换期间可能收到重复消息但不会丢失
+				old := s.pubsub
+				if err = s.subscribe(client); err == nil {
+					_ = old.Close()
+					logger.Infof("%s %s resubscribed %v", namespace, s.name, s.channels)
+					break
+				}
+			}
+			logger.Errorf("redis: resubscribe %s %v error: %v", s.name, s.channels, err)
+			sleep(s.ctx, resubscribeBackoff)
+			if s.ctx.Err() != nil {
+				return
+			}
+		}
+	}
+}
+
+// receive 分发消息，订阅结束、PubSub 被关闭或实例被替换时返回
+func (s *Subscription) receive() {
+	ch := s.pubsub.Channel()
+	ticker := time.NewTicker(swapCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			// 等待处理中的消息完成
+			for i := 0; i < cap(s.sem); i++ {
+				s.sem <- struct{}{}
+			}
+			return
+		case <-ticker.C:
+			if client, err := s.c.Get(s.name); err != nil || client != s.client {
+				return
+			}
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			s.sem <- struct{}{}
+			go func() {
+				defer func() { <-s.sem }()
+				s.handle(msg)
+			}()
+		}
+	}
+}
+
+// handle 解开消息信封，按实例配置记录链路、监控与日志后交给处理函数
+func (s *Subscription) handle(m *redis.Message) {
+	start := time.Now()
+	headers, body := envelope.Decode([]byte(m.Payload))
+	opt, _ := s.c.config(s.name)
+	if opt == nil {
+		opt = &Options{}
+	}
+
+	// 处理中的消息不受订阅取消影响，保证处理函数完整执行
+	ctx := context.WithoutCancel(s.ctx)
+	var err error
+	if !opt.DisableTrace {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+		var span trace.Span
+		ctx, span = tracer.Start(ctx, m.Channel+" process",
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(
+				semconv.MessagingSystemKey.String(kind),
+				semconv.MessagingDestinationName(m.Channel),
+				semconv.MessagingOperationProcess,
+			),
+		)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	err = s.handler(ctx, &PubSubMessage{
+		Channel: m.Channel,
+		Pattern: m.Pattern,
+		Payload: body,
+		Header:  headers,
+	})
+	duration := time.Since(start)
+
+	addr := strings.Join(opt.Addrs, ",")
+	if !opt.DisableMetric {
+		code := codes.Ok
+		if err != nil {
+			code = codes.Error
+		}
+		consumerRequests.With(kind, addr, m.Channel, m.Pattern, code.String()).Inc()
+		consumerSeconds.With(kind, addr, m.Channel, m.Pattern).Observe(float64(duration.Milliseconds()))
+	}
+
+	if !opt.DisableLogging {
+		fields := map[string]interface{}{
+			"kind":      "mq",
+			"component": kind,
+			"name":      s.name,
+			"channel":   m.Channel,
+			"pattern":   m.Pattern,
+			"duration":  timex.Duration(duration),
+		}
+		log := logger.WithContext(ctx)
+		if err != nil {
+			fields["error"] = err
+			log.WithFields(fields).Error("redis subscriber")
+		} else {
+			log.WithFields(fields).Info("redis subscriber")
+		}
+	}
+}
+
+// closeSubscriptions 取消全部订阅并等待处理中的消息完成
+func (c *Component) closeSubscriptions(ctx context.Context) error {
+	var wg sync.WaitGroup
+	c.subs.Range(func(key, _ interface{}) bool {
+		wg.Add(1)
+		go func(s *Subscription) {
+			defer wg.Done()
+			_ = s.Close()
+		}(key.(*Subscription))
+		return true
+	})
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("redis: close subscriptions %w", ctx.Err())
+	}
+}
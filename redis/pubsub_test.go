@@ -0,0 +1,124 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nextmicro/next-component/internal/envelope"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestComponent_SubscribeEnvelope(t *testing.T) {
+	c, _, client := newTestComponent(t, &Options{DisableTrace: true})
+	ctx := context.Background()
+
+	received := make(chan *PubSubMessage, 3)
+	sub, err := c.Subscribe(ctx, "", []string{"events"}, func(_ context.Context, msg *PubSubMessage) error {
+		received <- msg
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer func() { _ = sub.Close() }()
+
+	headers := map[string]string{"x-request-id": "1"}
+	enveloped, err := envelope.Encode(headers, []byte("enveloped"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 直接 PUBLISH 的普通消息、携带信封的消息与组件发布的消息都能被正确解包
+	if err = client.Publish(ctx, "events", "raw").Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Publish(ctx, "events", enveloped).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Publish(ctx, "", "events", []byte("component")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	want := []struct {
+		payload string
+		header  map[string]string
+	}{
+		{"raw", nil},
+		{"enveloped", headers},
+		{"component", nil},
+	}
+	for _, w := range want {
+		select {
+		case msg := <-received:
+			if string(msg.Payload) != w.payload || !reflect.DeepEqual(msg.Header, w.header) || msg.Channel != "events" {
+				t.Fatalf("message = %q %v on %s, want %q %v", msg.Payload, msg.Header, msg.Channel, w.payload, w.header)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("message %q not received", w.payload)
+		}
+	}
+}
+
+// fixedPropagator 总是写入固定的 header，用于验证链路信息是否写入信封
+type fixedPropagator struct{}
+
+func (fixedPropagator) Inject(_ context.Context, carrier propagation.TextMapCarrier) {
+	carrier.Set("traceparent", "fixed")
+}
+
+func (fixedPropagator) Extract(ctx context.Context, _ propagation.TextMapCarrier) context.Context {
+	return ctx
+}
+
+func (fixedPropagator) Fields() []string { return []string{"traceparent"} }
+
+func TestComponent_PublishEnvelope(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(fixedPropagator{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	tests := []struct {
+		name     string
+		opt      Options
+		envelope bool
+		want     map[string]string
+	}{
+		{name: "raw by default"},
+		{name: "enabled", opt: Options{EnableEnvelope: true}, envelope: true, want: map[string]string{"traceparent": "fixed"}},
+		// 没有 header 时不写入信封
+		{name: "enabled without trace", opt: Options{EnableEnvelope: true, DisableTrace: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := tt.opt
+			c, _, client := newTestComponent(t, &opt)
+			ctx := context.Background()
+
+			// 普通的 redis 订阅方直接读取消息
+			sub := client.Subscribe(ctx, "events")
+			defer func() { _ = sub.Close() }()
+			if _, err := sub.Receive(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := c.Publish(ctx, "", "events", []byte("payload")); err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+			msg, err := sub.ReceiveMessage(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := msg.Payload != "payload"; got != tt.envelope {
+				t.Fatalf("payload = %q, enveloped = %v, want %v", msg.Payload, got, tt.envelope)
+			}
+			headers, body := envelope.Decode([]byte(msg.Payload))
+			if string(body) != "payload" || len(headers) != len(tt.want) || (len(tt.want) > 0 && !reflect.DeepEqual(headers, tt.want)) {
+				t.Fatalf("envelope = %v %q, want %v payload", headers, body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	prom "github.com/go-kratos/kratos/contrib/metrics/prometheus/v2"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/nextmicro/logger"
+	"github.com/nextmicro/next/pkg/metrics"
+)
+
+// ErrLimitExceed is service unavailable due to rate limit exceeded.
+var ErrLimitExceed = errors.New(429, "RATELIMIT", "service unavailable due to rate limit exceeded")
+
+// Server 返回 kratos 服务端限流中间件，每个 key 按 limit 限流，超限时返回 ErrLimitExceed，
+// 并在响应头中写入 X-RateLimit-Remaining 与 Retry-After
+func Server(limiter *Limiter, limit Limit, opts ...MiddlewareOption) middleware.Middleware {
+	op := &middlewareOptions{
+		keyFunc:  operation,
+		failOpen: true,
+	}
+	for _, o := range opts {
+		o(op)
+	}
+	limited := prom.NewCounter(metrics.MetricRateLimitTotal)
+
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			key := op.keyFunc(ctx, req)
+			res, err := limiter.Allow(ctx, key, limit)
+			if err != nil {
+				logger.WithContext(ctx).WithFields(map[string]interface{}{
+					"kind":      "server",
+					"component": "ratelimit",
+					"key":       key,
+					"error":     err,
+				}).Error("redis ratelimit")
+				if op.failOpen {
+					return handler(ctx, req)
+				}
+				return nil, ErrLimitExceed
+			}
+
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				tr.ReplyHeader().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+				if !res.Allowed {
+					tr.ReplyHeader().Set("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+				}
+			}
+			if !res.Allowed {
+				limited.With("redis", op.name, operation(ctx, req)).Inc()
+				return nil, ErrLimitExceed
+			}
+
+			return handler(ctx, req)
+		}
+	}
+}
+
+func operation(ctx context.Context, _ interface{}) string {
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		return tr.Operation()
+	}
+	return ""
+}
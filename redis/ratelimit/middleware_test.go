@@ -0,0 +1,32 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestServer(t *testing.T) {
+	l, mr := newTestLimiter(t)
+	handler := func(context.Context, interface{}) (interface{}, error) { return "ok", nil }
+	keyFunc := WithKeyFunc(func(context.Context, interface{}) string { return "user" })
+
+	h := Server(l, PerHour(1), keyFunc)(handler)
+	if reply, err := h(context.Background(), nil); err != nil || reply != "ok" {
+		t.Fatalf("handler() = %v, %v, want ok", reply, err)
+	}
+	if _, err := h(context.Background(), nil); !errors.Is(err, ErrLimitExceed) {
+		t.Fatalf("handler() over limit error = %v, want %v", err, ErrLimitExceed)
+	}
+
+	// redis 不可用时默认放行，关闭后拒绝
+	mr.Close()
+	if reply, err := h(context.Background(), nil); err != nil || reply != "ok" {
+		t.Fatalf("handler() fail open = %v, %v, want ok", reply, err)
+	}
+	h = Server(l, PerHour(1), keyFunc, WithFailOpen(false))(handler)
+	if _, err := h(context.Background(), nil); !errors.Is(err, ErrLimitExceed) {
+		t.Fatalf("handler() fail closed error = %v, want %v", err, ErrLimitExceed)
+	}
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+)
+
+// Option is limiter option.
+type Option func(o *options)
+
+type options struct {
+	// key prefix, default "ratelimit:".
+	prefix string
+	// limit algorithm, default GCRA.
+	algorithm Algorithm
+}
+
+// WithPrefix 设置限流 key 的前缀，默认 ratelimit:
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithAlgorithm 设置限流算法，默认 GCRA
+func WithAlgorithm(algorithm Algorithm) Option {
+	return func(o *options) {
+		o.algorithm = algorithm
+	}
+}
+
+// MiddlewareOption is middleware option.
+type MiddlewareOption func(o *middlewareOptions)
+
+type middlewareOptions struct {
+	// key of the request, default the transport operation.
+	keyFunc func(ctx context.Context, req interface{}) string
+	// name label of rate limit metric.
+	name string
+	// allow requests when redis is unavailable, default true.
+	failOpen bool
+}
+
+// WithKeyFunc 设置限流 key，如按用户或客户端 IP 限流，默认按接口 operation 限流
+func WithKeyFunc(fn func(ctx context.Context, req interface{}) string) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.keyFunc = fn
+	}
+}
+
+// WithName with name label.
+func WithName(name string) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.name = name
+	}
+}
+
+// WithFailOpen 设置 redis 不可用时是否放行请求，默认放行
+func WithFailOpen(failOpen bool) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.failOpen = failOpen
+	}
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Algorithm 限流算法
+type Algorithm string
+
+const (
+	// GCRA 通用信元速率算法，等价于令牌桶，允许 Burst 个请求的突发
+	GCRA Algorithm = "gcra"
+	// SlidingWindow 滑动窗口计数，Period 内最多 Rate 个请求
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+const defaultPrefix = "ratelimit:"
+
+// Limit 限流规则，Period 内允许 Rate 个请求
+type Limit struct {
+	Rate   int           // 每个周期允许的请求数
+	Period time.Duration // 周期
+	Burst  int           // 突发容量，仅 GCRA 使用，默认等于 Rate
+}
+
+// PerSecond 每秒 rate 个请求
+func PerSecond(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Second, Burst: rate}
+}
+
+// PerMinute 每分钟 rate 个请求
+func PerMinute(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Minute, Burst: rate}
+}
+
+// PerHour 每小时 rate 个请求
+func PerHour(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Hour, Burst: rate}
+}
+
+// Result 限流结果
+type Result struct {
+	Allowed    bool          // 是否允许
+	Remaining  int           // 剩余可用请求数
+	RetryAfter time.Duration // 被拒绝时距离下次允许的等待时间，允许时为0
+}
+
+// Limiter 基于 redis Lua 脚本的分布式限流器，多个实例共享同一 redis 时限流全局生效
+type Limiter struct {
+	client redis.UniversalClient
+	opt    *options
+}
+
+// New 创建限流器，client 可以是任意 redis.UniversalClient
+func New(client redis.UniversalClient, opts ...Option) *Limiter {
+	opt := &options{
+		prefix:    defaultPrefix,
+		algorithm: GCRA,
+	}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	return &Limiter{client: client, opt: opt}
+}
+
+// Allow 按 limit 对 key 消耗1个请求
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	return l.AllowN(ctx, key, limit, 1)
+}
+
+// AllowN 按 limit 对 key 消耗 n 个请求，被拒绝时不消耗
+func (l *Limiter) AllowN(ctx context.Context, key string, limit Limit, n int) (*Result, error) {
+	if limit.Rate <= 0 || limit.Period <= 0 {
+		return nil, fmt.Errorf("ratelimit: invalid limit %+v", limit)
+	}
+
+	var (
+		values []int64
+		err    error
+	)
+	key = l.opt.prefix + key
+	switch l.opt.algorithm {
+	case GCRA:
+		burst := limit.Burst
+		if burst <= 0 {
+			burst = limit.Rate
+		}
+		values, err = gcraScript.Run(ctx, l.client, []string{key}, burst, limit.Rate, limit.Period.Seconds(), n).Int64Slice()
+	case SlidingWindow:
+		values, err = slidingWindowScript.Run(ctx, l.client, []string{key}, limit.Rate, limit.Period.Milliseconds(), n).Int64Slice()
+	default:
+		return nil, fmt.Errorf("ratelimit: unknown algorithm %q", l.opt.algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 3 {
+		return nil, fmt.Errorf("ratelimit: unexpected script result %v", values)
+	}
+
+	res := &Result{Allowed: values[0] == 1, Remaining: int(values[1])}
+	if values[2] > 0 {
+		res.RetryAfter = time.Duration(values[2]) * time.Millisecond
+	}
+	return res, nil
+}
+
+// Reset 清除 key 的限流状态
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	return l.client.Del(ctx, l.opt.prefix+key).Err()
+}
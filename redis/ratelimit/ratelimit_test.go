@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestLimiter(t *testing.T, opts ...Option) (*Limiter, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return New(client, opts...), mr
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	for _, algorithm := range []Algorithm{GCRA, SlidingWindow} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			l, mr := newTestLimiter(t, WithAlgorithm(algorithm))
+			ctx := context.Background()
+			limit := PerHour(3)
+
+			for i := 0; i < 3; i++ {
+				res, err := l.Allow(ctx, "key", limit)
+				if err != nil {
+					t.Fatalf("Allow() error = %v", err)
+				}
+				if !res.Allowed || res.Remaining != 2-i || res.RetryAfter != 0 {
+					t.Fatalf("Allow() #%d = %+v, want allowed with %d remaining", i, res, 2-i)
+				}
+			}
+
+			res, err := l.Allow(ctx, "key", limit)
+			if err != nil {
+				t.Fatalf("Allow() error = %v", err)
+			}
+			if res.Allowed || res.Remaining != 0 || res.RetryAfter <= 0 || res.RetryAfter > time.Hour {
+				t.Fatalf("Allow() over limit = %+v, want rejected with retry after in (0, 1h]", res)
+			}
+
+			// 其他 key 不受影响
+			if res, err = l.Allow(ctx, "other", limit); err != nil || !res.Allowed {
+				t.Fatalf("Allow() other key = %+v, %v, want allowed", res, err)
+			}
+			if !mr.Exists(defaultPrefix + "key") {
+				t.Fatalf("state key %q not found", defaultPrefix+"key")
+			}
+
+			// Reset 后重新计数
+			if err = l.Reset(ctx, "key"); err != nil {
+				t.Fatalf("Reset() error = %v", err)
+			}
+			if res, err = l.Allow(ctx, "key", limit); err != nil || !res.Allowed {
+				t.Fatalf("Allow() after Reset = %+v, %v, want allowed", res, err)
+			}
+		})
+	}
+}
+
+func TestLimiter_AllowNRejectedDoesNotConsume(t *testing.T) {
+	for _, algorithm := range []Algorithm{GCRA, SlidingWindow} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			l, _ := newTestLimiter(t, WithAlgorithm(algorithm))
+			ctx := context.Background()
+			limit := PerHour(5)
+
+			if res, err := l.AllowN(ctx, "key", limit, 3); err != nil || !res.Allowed || res.Remaining != 2 {
+				t.Fatalf("AllowN(3) = %+v, %v, want allowed with 2 remaining", res, err)
+			}
+			if res, err := l.AllowN(ctx, "key", limit, 3); err != nil || res.Allowed {
+				t.Fatalf("AllowN(3) over limit = %+v, %v, want rejected", res, err)
+			}
+			if res, err := l.AllowN(ctx, "key", limit, 2); err != nil || !res.Allowed || res.Remaining != 0 {
+				t.Fatalf("AllowN(2) = %+v, %v, want allowed with 0 remaining", res, err)
+			}
+		})
+	}
+}
+
+func TestLimiter_GCRABurst(t *testing.T) {
+	l, _ := newTestLimiter(t)
+	ctx := context.Background()
+	limit := Limit{Rate: 10, Period: time.Hour, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		if res, err := l.Allow(ctx, "key", limit); err != nil || !res.Allowed {
+			t.Fatalf("Allow() #%d = %+v, %v, want allowed", i, res, err)
+		}
+	}
+	res, err := l.Allow(ctx, "key", limit)
+	if err != nil || res.Allowed {
+		t.Fatalf("Allow() over burst = %+v, %v, want rejected", res, err)
+	}
+	// 突发用尽后按 Period/Rate 的间隔恢复
+	if interval := limit.Period / time.Duration(limit.Rate); res.RetryAfter > interval {
+		t.Fatalf("RetryAfter = %v, want <= %v", res.RetryAfter, interval)
+	}
+}
+
+func TestLimiter_InvalidLimit(t *testing.T) {
+	l, _ := newTestLimiter(t)
+	for _, limit := range []Limit{{}, {Rate: 1}, {Period: time.Second}} {
+		if _, err := l.Allow(context.Background(), "key", limit); err == nil {
+			t.Fatalf("Allow(%+v) error = nil, want invalid limit", limit)
+		}
+	}
+
+	l, _ = newTestLimiter(t, WithAlgorithm("unknown"))
+	if _, err := l.Allow(context.Background(), "key", PerSecond(1)); err == nil {
+		t.Fatal("Allow() with unknown algorithm error = nil")
+	}
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import "github.com/redis/go-redis/v9"
+
+// gcraScript GCRA 算法，KEYS[1] 保存理论到达时间（TAT），ARGV 为 burst、rate、period(秒)、cost，
+// 返回 {allowed, remaining, retry_after(ms)}
+var gcraScript = redis.NewScript(`
+redis.replicate_commands()
+
+local key = KEYS[1]
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local period = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+
+local emission_interval = period / rate
+local increment = emission_interval * cost
+local burst_offset = emission_interval * burst
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+
+local tat = tonumber(redis.call("GET", key))
+if not tat or tat < now then
+	tat = now
+end
+
+local new_tat = tat + increment
+local diff = now - (new_tat - burst_offset)
+if diff < 0 then
+	return {0, 0, math.ceil(-diff * 1000)}
+end
+
+local reset_after = new_tat - now
+if reset_after > 0 then
+	redis.call("SET", key, new_tat, "EX", math.ceil(reset_after))
+end
+return {1, math.floor(diff / emission_interval), -1}
+`)
+
+// slidingWindowScript 滑动窗口计数，按上一窗口剩余时间占比加权估算当前窗口内的请求数，
+// KEYS[1] 为 hash 保存窗口起始时间与前后两个窗口的计数，ARGV 为 limit、window(ms)、cost，
+// 返回 {allowed, remaining, retry_after(ms)}
+var slidingWindowScript = redis.NewScript(`
+redis.replicate_commands()
+
+local key = KEYS[1]
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local start = now - (now % window)
+
+local vals = redis.call("HMGET", key, "start", "cur", "prev")
+local last = tonumber(vals[1])
+local cur = tonumber(vals[2]) or 0
+local prev = tonumber(vals[3]) or 0
+if last ~= start then
+	if last == start - window then
+		prev = cur
+	else
+		prev = 0
+	end
+	cur = 0
+end
+
+local elapsed = now - start
+local used = prev * (window - elapsed) / window + cur
+if used + cost > limit then
+	local retry_after = window - elapsed
+	if prev > 0 and cur + cost <= limit then
+		retry_after = math.ceil(window - elapsed - (limit - cur - cost) * window / prev)
+	end
+	return {0, math.max(0, math.floor(limit - used)), retry_after}
+end
+
+redis.call("HSET", key, "start", start, "cur", cur + cost, "prev", prev)
+redis.call("PEXPIRE", key, window * 2)
+return {1, math.floor(limit - used - cost), -1}
+`)
@@ -0,0 +1,43 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	prom "github.com/go-kratos/kratos/contrib/metrics/prometheus/v2"
+	"github.com/nextmicro/logger"
+	"github.com/nextmicro/next-component/internal/connect"
+	m "github.com/nextmicro/next/pkg/metrics"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// connects 记录启动连接每次尝试的结果，command 标签为 connect
+var connects = prom.NewCounter(m.DBSystemMetricRequests)
+
+// retry 执行启动连接检查，失败时按指数退避重试，直到成功、达到最大尝试次数或超过总时长。
+func retry(name string, cfg *Options, ping func(ctx context.Context) error) error {
+	addr := strings.Join(cfg.Addrs, ",")
+	err := connect.Retry{
+		MaxAttempts: cfg.ConnectMaxAttempts,
+		MinBackoff:  cfg.ConnectMinBackoff,
+		MaxBackoff:  cfg.ConnectMaxBackoff,
+		Timeout:     cfg.ConnectTimeout,
+		OnAttempt: func(attempt int, err error, backoff time.Duration) {
+			if err == nil {
+				connects.With("redis", name, addr, "connect", codes.Ok.String()).Inc()
+				return
+			}
+
+			connects.With("redis", name, addr, "connect", codes.Error.String()).Inc()
+			if backoff > 0 {
+				logger.Warnf("%s %s connect attempt %d/%d error: %v, retry after %s", namespace, name, attempt, cfg.ConnectMaxAttempts, err, backoff)
+			}
+		},
+	}.Do(ping)
+	if err != nil {
+		return fmt.Errorf("redis: connect %s %w", name, err)
+	}
+	return nil
+}
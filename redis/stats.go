@@ -35,15 +35,11 @@ func (s *Stat) Run(ctx context.Context) {
 		case <-ticker.C:
 			Redis.clients.Range(func(key, val interface{}) bool {
 				name := key.(string)
-				obj := val.(*redis.Client)
-				stats := obj.PoolStats()
-				addrs := strings.Join(Redis.opts[name].Addrs, ",")
-				s.stats.With(namespace, name, addrs, "hits").Set(float64(stats.Hits))
-				s.stats.With(namespace, name, addrs, "misses").Set(float64(stats.Misses))
-				s.stats.With(namespace, name, addrs, "timeouts").Set(float64(stats.Timeouts))
-				s.stats.With(namespace, name, addrs, "total_conns").Set(float64(stats.TotalConns))
-				s.stats.With(namespace, name, addrs, "idle_conns").Set(float64(stats.IdleConns))
-				s.stats.With(namespace, name, addrs, "stale_conns").Set(float64(stats.StaleConns))
+				opt, ok := Redis.config(name)
+				if !ok {
+					return true
+				}
+				s.collect(ctx, name, val.(redis.UniversalClient), opt)
 				return true
 			})
 		}
@@ -53,3 +49,43 @@ Close:
 
 	logger.Info("redis: stats metrics stop")
 }
+
+// collect 记录命名实例的连接池统计，集群与 ring 客户端按节点地址分别记录
+func (s *Stat) collect(ctx context.Context, name string, client redis.UniversalClient, opt *Options) {
+	ctx, cancel := context.WithTimeout(ctx, s.interval)
+	defer cancel()
+
+	shard := func(ctx context.Context, shard *redis.Client) error {
+		s.record(name, shard.Options().Addr, shard.PoolStats())
+		return nil
+	}
+
+	var err error
+	switch c := client.(type) {
+	case *redis.ClusterClient:
+		err = c.ForEachShard(ctx, shard)
+	case *redis.Ring:
+		err = c.ForEachShard(ctx, shard)
+	case *redis.Client:
+		addr := c.Options().Addr
+		// 哨兵模式下 Options().Addr 不是真实地址，使用哨兵地址
+		if opt.MasterName != "" {
+			addr = strings.Join(opt.Addrs, ",")
+		}
+		s.record(name, addr, c.PoolStats())
+	default:
+		s.record(name, strings.Join(opt.Addrs, ","), c.PoolStats())
+	}
+	if err != nil {
+		logger.Warnf("redis: %s stats metrics error: %v", name, err)
+	}
+}
+
+func (s *Stat) record(name, addr string, stats *redis.PoolStats) {
+	s.stats.With(namespace, name, addr, "hits").Set(float64(stats.Hits))
+	s.stats.With(namespace, name, addr, "misses").Set(float64(stats.Misses))
+	s.stats.With(namespace, name, addr, "timeouts").Set(float64(stats.Timeouts))
+	s.stats.With(namespace, name, addr, "total_conns").Set(float64(stats.TotalConns))
+	s.stats.With(namespace, name, addr, "idle_conns").Set(float64(stats.IdleConns))
+	s.stats.With(namespace, name, addr, "stale_conns").Set(float64(stats.StaleConns))
+}
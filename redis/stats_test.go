@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// gaugeRecorder 记录每组标签最后一次设置的值
+type gaugeRecorder struct {
+	values map[string]float64
+	lvs    []string
+}
+
+func newGaugeRecorder() *gaugeRecorder {
+	return &gaugeRecorder{values: make(map[string]float64)}
+}
+
+func (g *gaugeRecorder) With(lvs ...string) metrics.Gauge {
+	return &gaugeRecorder{values: g.values, lvs: lvs}
+}
+
+func (g *gaugeRecorder) Set(v float64) { g.values[strings.Join(g.lvs, "|")] = v }
+
+func (g *gaugeRecorder) Add(v float64) { g.values[strings.Join(g.lvs, "|")] += v }
+
+func (g *gaugeRecorder) Sub(v float64) { g.values[strings.Join(g.lvs, "|")] -= v }
+
+func (g *gaugeRecorder) has(name, addr string) bool {
+	_, ok := g.values[strings.Join([]string{namespace, name, addr, "total_conns"}, "|")]
+	return ok
+}
+
+func TestStat_CollectRing(t *testing.T) {
+	mr1, mr2 := miniredis.RunT(t), miniredis.RunT(t)
+	ring := redis.NewRing(&redis.RingOptions{Addrs: map[string]string{"a": mr1.Addr(), "b": mr2.Addr()}})
+	t.Cleanup(func() { _ = ring.Close() })
+
+	g := newGaugeRecorder()
+	s := &Stat{interval: time.Second, stats: g}
+	s.collect(context.Background(), "ring", ring, &Options{Addrs: []string{mr1.Addr(), mr2.Addr()}})
+
+	// 按节点地址分别记录
+	for _, addr := range []string{mr1.Addr(), mr2.Addr()} {
+		if !g.has("ring", addr) {
+			t.Fatalf("no stats for shard %s, got %v", addr, g.values)
+		}
+	}
+	if g.has("ring", mr1.Addr()+","+mr2.Addr()) {
+		t.Fatal("ring stats recorded with the joined addrs")
+	}
+}
+
+func TestStat_CollectClient(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	g := newGaugeRecorder()
+	s := &Stat{interval: time.Second, stats: g}
+	s.collect(context.Background(), "default", client, &Options{Addrs: []string{mr.Addr()}})
+	// 哨兵模式下使用哨兵地址
+	s.collect(context.Background(), "sentinel", client, &Options{Addrs: []string{"s1:26379", "s2:26379"}, MasterName: "master"})
+
+	if !g.has("default", mr.Addr()) {
+		t.Fatalf("no stats for %s, got %v", mr.Addr(), g.values)
+	}
+	if !g.has("sentinel", "s1:26379,s2:26379") {
+		t.Fatalf("no stats for the sentinel addrs, got %v", g.values)
+	}
+}
@@ -0,0 +1,429 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	prom "github.com/go-kratos/kratos/contrib/metrics/prometheus/v2"
+	"github.com/nextmicro/gokit/timex"
+	"github.com/nextmicro/logger"
+	"github.com/nextmicro/next/pkg/metrics"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	defaultStreamBatchSize     = 10
+	defaultStreamBlock         = 2 * time.Second
+	defaultStreamMinIdle       = time.Minute
+	defaultStreamClaimInterval = 30 * time.Second
+)
+
+var (
+	consumerRequests = prom.NewCounter(metrics.MessagingConsumerMetricRequests)
+	consumerSeconds  = prom.NewHistogram(metrics.MessagingConsumerMetricMillisecond)
+)
+
+// Stream 消费组配置
+type Stream struct {
+	Stream           string        `json:"stream"`             // stream 名称
+	Group            string        `json:"group"`              // 消费组，不存在时自动创建
+	Consumer         string        `json:"consumer"`           // 消费者名称，默认为 hostname-pid
+	StartID          string        `json:"start_id"`           // 创建消费组时的起始 ID，默认 $ 即只消费新消息
+	Concurrency      int           `json:"concurrency"`        // 并发处理的协程数，默认1
+	BatchSize        int64         `json:"batch_size"`         // 每次 XREADGROUP、XPENDING 读取的条数，默认10
+	Block            time.Duration `json:"block"`              // XREADGROUP 阻塞时间，默认2s，Stop 最多等待该时长
+	MinIdle          time.Duration `json:"min_idle"`           // 未确认消息超过该时长后被重新认领，默认1m
+	ClaimInterval    time.Duration `json:"claim_interval"`     // 认领超时未确认消息的间隔，默认30s
+	MaxDeliveries    int64         `json:"max_deliveries"`     // 最大投递次数，超过后投递到死信 stream，默认0即不限制
+	DeadLetterStream string        `json:"dead_letter_stream"` // 死信 stream，为空时超过最大投递次数的消息直接确认丢弃
+}
+
+// StreamMessage 消费到的 stream 消息
+type StreamMessage struct {
+	redis.XMessage
+	Stream     string // stream 名称
+	Group      string // 消费组
+	Deliveries int64  // 投递次数，首次投递为1
+}
+
+// StreamHandler stream 消息处理函数，返回 nil 时确认消息，
+// 返回 error 时消息保留在待确认列表中，超过 min_idle 后重新投递
+type StreamHandler func(ctx context.Context, msg *StreamMessage) error
+
+type streamWorker struct {
+	name    string
+	cfg     Stream
+	handler StreamHandler
+}
+
+// HandleStream 为命名实例上配置的 stream 注册处理函数，必须在 Start 之前调用
+func (c *Component) HandleStream(name, stream string, handler StreamHandler) error {
+	if name == "" {
+		name = defaultName
+	}
+
+	opt, ok := c.config(name)
+	if !ok {
+		return fmt.Errorf("redis: %w, group: %s", ErrInstanceNotFound, name)
+	}
+
+	var cfg *Stream
+	for i := range opt.Streams {
+		if opt.Streams[i].Stream == stream {
+			cfg = &opt.Streams[i]
+			break
+		}
+	}
+	if cfg == nil {
+		return fmt.Errorf("redis: stream %s not configured, group: %s", stream, name)
+	}
+	if cfg.Group == "" {
+		return fmt.Errorf("redis: stream %s group is empty, group: %s", stream, name)
+	}
+
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+	if c.streamCancel != nil {
+		return fmt.Errorf("redis: stream %s handle after start", stream)
+	}
+	key := name + "/" + stream
+	if _, ok := c.streams[key]; ok {
+		return fmt.Errorf("redis: stream %s already handled, group: %s", stream, name)
+	}
+	if c.streams == nil {
+		c.streams = make(map[string]*streamWorker)
+	}
+	c.streams[key] = &streamWorker{name: name, cfg: withStreamDefaults(*cfg), handler: handler}
+	return nil
+}
+
+func withStreamDefaults(cfg Stream) Stream {
+	if cfg.Consumer == "" {
+		hostname, _ := os.Hostname()
+		cfg.Consumer = hostname + "-" + strconv.Itoa(os.Getpid())
+	}
+	if cfg.StartID == "" {
+		cfg.StartID = "$"
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultStreamBatchSize
+	}
+	if cfg.Block <= 0 {
+		cfg.Block = defaultStreamBlock
+	}
+	if cfg.MinIdle <= 0 {
+		cfg.MinIdle = defaultStreamMinIdle
+	}
+	if cfg.ClaimInterval <= 0 {
+		cfg.ClaimInterval = defaultStreamClaimInterval
+	}
+	return cfg
+}
+
+// startStreams 创建消费组并为每个已注册的 stream 启动读取、认领与处理协程
+func (c *Component) startStreams(ctx context.Context) error {
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	c.streamCancel = cancel
+	for _, w := range c.streams {
+		client, err := c.Get(w.name)
+		if err != nil {
+			return err
+		}
+		err = client.XGroupCreateMkStream(ctx, w.cfg.Stream, w.cfg.Group, w.cfg.StartID).Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return fmt.Errorf("redis: stream %s create group %s %w", w.cfg.Stream, w.cfg.Group, err)
+		}
+
+		msgs := make(chan *StreamMessage)
+		c.streamWG.Add(2 + w.cfg.Concurrency)
+		go c.readStream(ctx, w, msgs)
+		go c.claimStream(ctx, w, msgs)
+		for i := 0; i < w.cfg.Concurrency; i++ {
+			go c.processStream(ctx, w, msgs)
+		}
+	}
+	return nil
+}
+
+// stopStreams 停止读取并等待处理中的消息完成
+func (c *Component) stopStreams(ctx context.Context) error {
+	c.streamMu.Lock()
+	cancel := c.streamCancel
+	c.streamMu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		c.streamWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("redis: stop streams %w", ctx.Err())
+	}
+}
+
+// readStream 通过 XREADGROUP 读取新消息，每次读取前重新获取实例以跟随热更新
+func (c *Component) readStream(ctx context.Context, w *streamWorker, msgs chan<- *StreamMessage) {
+	defer c.streamWG.Done()
+
+	for ctx.Err() == nil {
+		client, err := c.Get(w.name)
+		if err == nil {
+			var streams []redis.XStream
+			streams, err = client.XReadGroup(ctx, &redis.XReadGroupArgs{
+				Group:    w.cfg.Group,
+				Consumer: w.cfg.Consumer,
+				Streams:  []string{w.cfg.Stream, ">"},
+				Count:    w.cfg.BatchSize,
+				Block:    w.cfg.Block,
+			}).Result()
+			for _, stream := range streams {
+				for _, m := range stream.Messages {
+					if !dispatch(ctx, msgs, &StreamMessage{XMessage: m, Stream: w.cfg.Stream, Group: w.cfg.Group, Deliveries: 1}) {
+						return
+					}
+				}
+			}
+		}
+		if err != nil && !errors.Is(err, redis.Nil) && ctx.Err() == nil {
+			logger.Errorf("redis: stream %s read group %s error: %v", w.cfg.Stream, w.cfg.Group, err)
+			sleep(ctx, time.Second)
+		}
+	}
+}
+
+// claimStream 定期处理超过 min_idle 未确认的消息：超过最大投递次数的投递到死信 stream，
+// 其余认领后重新处理
+func (c *Component) claimStream(ctx context.Context, w *streamWorker, msgs chan<- *StreamMessage) {
+	defer c.streamWG.Done()
+
+	ticker := time.NewTicker(w.cfg.ClaimInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		client, err := c.Get(w.name)
+		if err != nil {
+			continue
+		}
+		if err = c.claim(ctx, client, w, msgs); err != nil && ctx.Err() == nil {
+			logger.Errorf("redis: stream %s claim group %s error: %v", w.cfg.Stream, w.cfg.Group, err)
+		}
+	}
+}
+
+// claim 按游标分页遍历空闲超过 min_idle 的待确认消息，超过最大投递次数的投递到死信 stream，
+// 其余通过 XCLAIM 认领恰好这些 ID，投递次数以同一次 XPENDING 的结果为准
+func (c *Component) claim(ctx context.Context, client redis.UniversalClient, w *streamWorker, msgs chan<- *StreamMessage) error {
+	start := "-"
+	for {
+		pending, err := client.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: w.cfg.Stream,
+			Group:  w.cfg.Group,
+			Idle:   w.cfg.MinIdle,
+			Start:  start,
+			End:    "+",
+			Count:  w.cfg.BatchSize,
+		}).Result()
+		if err != nil {
+			return err
+		}
+
+		ids := make([]string, 0, len(pending))
+		deliveries := make(map[string]int64, len(pending))
+		for _, p := range pending {
+			if w.cfg.MaxDeliveries > 0 && p.RetryCount >= w.cfg.MaxDeliveries {
+				if err = c.deadLetter(ctx, client, w, p); err != nil {
+					return err
+				}
+				continue
+			}
+			ids = append(ids, p.ID)
+			deliveries[p.ID] = p.RetryCount
+		}
+
+		if len(ids) > 0 {
+			// 期间被其他消费者认领的消息空闲时间已重置，不会被重复认领
+			claimed, err := client.XClaim(ctx, &redis.XClaimArgs{
+				Stream:   w.cfg.Stream,
+				Group:    w.cfg.Group,
+				Consumer: w.cfg.Consumer,
+				MinIdle:  w.cfg.MinIdle,
+				Messages: ids,
+			}).Result()
+			if err != nil {
+				return err
+			}
+			for _, m := range claimed {
+				msg := &StreamMessage{XMessage: m, Stream: w.cfg.Stream, Group: w.cfg.Group, Deliveries: deliveries[m.ID] + 1}
+				if !dispatch(ctx, msgs, msg) {
+					return nil
+				}
+			}
+		}
+
+		if int64(len(pending)) < w.cfg.BatchSize {
+			return nil
+		}
+		start = nextStreamID(pending[len(pending)-1].ID)
+	}
+}
+
+// nextStreamID 返回紧随 id 之后的 stream ID，用于 XPENDING 分页
+func nextStreamID(id string) string {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return id
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return id
+	}
+	return ms + "-" + strconv.FormatUint(n+1, 10)
+}
+
+// deadLetter 将超过最大投递次数的消息写入死信 stream 后确认，未配置死信 stream 时直接确认
+func (c *Component) deadLetter(ctx context.Context, client redis.UniversalClient, w *streamWorker, p redis.XPendingExt) error {
+	fields := map[string]interface{}{
+		"kind":       "mq",
+		"component":  kind,
+		"name":       w.name,
+		"stream":     w.cfg.Stream,
+		"group":      w.cfg.Group,
+		"id":         p.ID,
+		"deliveries": p.RetryCount,
+	}
+
+	if w.cfg.DeadLetterStream != "" {
+		msgs, err := client.XRangeN(ctx, w.cfg.Stream, p.ID, p.ID, 1).Result()
+		if err != nil {
+			return err
+		}
+		// 消息已被删除时只需确认
+		if len(msgs) > 0 {
+			values := map[string]interface{}{
+				"_stream":     w.cfg.Stream,
+				"_group":      w.cfg.Group,
+				"_id":         p.ID,
+				"_deliveries": p.RetryCount,
+			}
+			for k, v := range msgs[0].Values {
+				values[k] = v
+			}
+			if err = client.XAdd(ctx, &redis.XAddArgs{Stream: w.cfg.DeadLetterStream, Values: values}).Err(); err != nil {
+				return err
+			}
+		}
+		fields["dead_letter_stream"] = w.cfg.DeadLetterStream
+	}
+
+	if err := client.XAck(ctx, w.cfg.Stream, w.cfg.Group, p.ID).Err(); err != nil {
+		return err
+	}
+	logger.WithContext(ctx).WithFields(fields).Warn("redis stream dead letter")
+	return nil
+}
+
+// processStream 调用处理函数，成功后确认消息
+func (c *Component) processStream(ctx context.Context, w *streamWorker, msgs <-chan *StreamMessage) {
+	defer c.streamWG.Done()
+
+	for {
+		var msg *StreamMessage
+		select {
+		case <-ctx.Done():
+			return
+		case msg = <-msgs:
+		}
+
+		// 处理中的消息不受 Stop 取消影响，保证处理函数完整执行并确认
+		handleCtx := context.WithoutCancel(ctx)
+		start := time.Now()
+		err := w.handler(handleCtx, msg)
+		if err == nil {
+			var client redis.UniversalClient
+			if client, err = c.Get(w.name); err == nil {
+				err = client.XAck(handleCtx, msg.Stream, msg.Group, msg.ID).Err()
+			}
+		}
+		c.observeStream(handleCtx, w, msg, time.Since(start), err)
+	}
+}
+
+func (c *Component) observeStream(ctx context.Context, w *streamWorker, msg *StreamMessage, duration time.Duration, err error) {
+	opt, ok := c.config(w.name)
+	if !ok {
+		return
+	}
+
+	addr := strings.Join(opt.Addrs, ",")
+	if !opt.DisableMetric {
+		code := codes.Ok
+		if err != nil {
+			code = codes.Error
+		}
+		consumerRequests.With(kind, addr, msg.Stream, msg.Group, code.String()).Inc()
+		consumerSeconds.With(kind, addr, msg.Stream, msg.Group).Observe(float64(duration.Milliseconds()))
+	}
+
+	if !opt.DisableLogging {
+		fields := map[string]interface{}{
+			"kind":       "mq",
+			"component":  kind,
+			"name":       w.name,
+			"stream":     msg.Stream,
+			"group":      msg.Group,
+			"id":         msg.ID,
+			"deliveries": msg.Deliveries,
+			"duration":   timex.Duration(duration),
+		}
+		log := logger.WithContext(ctx)
+		if err != nil {
+			fields["error"] = err
+			log.WithFields(fields).Error("redis stream consumer")
+		} else {
+			log.WithFields(fields).Info("redis stream consumer")
+		}
+	}
+}
+
+// dispatch 将消息交给处理协程，ctx 结束时返回 false，未处理的消息保留在待确认列表中
+func dispatch(ctx context.Context, msgs chan<- *StreamMessage, msg *StreamMessage) bool {
+	select {
+	case msgs <- msg:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
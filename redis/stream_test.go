@@ -0,0 +1,172 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestComponent(t *testing.T, opt *Options) (*Component, *miniredis.Miniredis, redis.UniversalClient) {
+	mr := miniredis.RunT(t)
+	opt.Addrs = []string{mr.Addr()}
+	opt.DisableMetric, opt.DisableLogging = true, true
+	client := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: opt.Addrs})
+	t.Cleanup(func() { _ = client.Close() })
+
+	c := &Component{opts: map[string]*Options{defaultName: opt}}
+	c.clients.Store(defaultName, client)
+	return c, mr, client
+}
+
+func TestNextStreamID(t *testing.T) {
+	tests := map[string]string{
+		"1-0":          "1-1",
+		"1700000000-9": "1700000000-10",
+		"invalid":      "invalid",
+	}
+	for id, want := range tests {
+		if got := nextStreamID(id); got != want {
+			t.Fatalf("nextStreamID(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestComponent_ClaimDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	cfg := withStreamDefaults(Stream{
+		Stream:           "orders",
+		Group:            "g",
+		Consumer:         "c1",
+		MinIdle:          time.Millisecond,
+		BatchSize:        2,
+		MaxDeliveries:    2,
+		DeadLetterStream: "orders.dlq",
+	})
+	c, _, client := newTestComponent(t, &Options{Streams: []Stream{cfg}})
+	w := &streamWorker{name: defaultName, cfg: cfg}
+
+	if err := client.XGroupCreateMkStream(ctx, cfg.Stream, cfg.Group, "0").Err(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := client.XAdd(ctx, &redis.XAddArgs{Stream: cfg.Stream, Values: map[string]interface{}{"n": i}}).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 首次投递，全部消息进入待确认列表
+	read, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{Group: cfg.Group, Consumer: "c0", Streams: []string{cfg.Stream, ">"}}).Result()
+	if err != nil || len(read[0].Messages) != 5 {
+		t.Fatalf("XReadGroup = %v, %v", read, err)
+	}
+
+	claim := func() []*StreamMessage {
+		time.Sleep(5 * time.Millisecond)
+		msgs := make(chan *StreamMessage, 10)
+		if err := c.claim(ctx, client, w, msgs); err != nil {
+			t.Fatal(err)
+		}
+		close(msgs)
+		var got []*StreamMessage
+		for m := range msgs {
+			got = append(got, m)
+		}
+		return got
+	}
+
+	// 跨多页认领全部消息，投递次数为2
+	got := claim()
+	if len(got) != 5 {
+		t.Fatalf("claimed %d messages, want 5", len(got))
+	}
+	for _, m := range got {
+		if m.Deliveries != 2 {
+			t.Fatalf("message %s deliveries = %d, want 2", m.ID, m.Deliveries)
+		}
+	}
+
+	// 达到最大投递次数，全部进入死信 stream 并确认
+	if got = claim(); len(got) != 0 {
+		t.Fatalf("claimed %d messages after max deliveries", len(got))
+	}
+	dlq, err := client.XRange(ctx, cfg.DeadLetterStream, "-", "+").Result()
+	if err != nil || len(dlq) != 5 {
+		t.Fatalf("dead letters = %v, %v", dlq, err)
+	}
+	if dlq[0].Values["_stream"] != cfg.Stream || dlq[0].Values["n"] != "0" {
+		t.Fatalf("dead letter values = %v", dlq[0].Values)
+	}
+	pending, err := client.XPending(ctx, cfg.Stream, cfg.Group).Result()
+	if err != nil || pending.Count != 0 {
+		t.Fatalf("pending = %+v, %v", pending, err)
+	}
+}
+
+func TestComponent_HandleStream(t *testing.T) {
+	ctx := context.Background()
+	c, _, client := newTestComponent(t, &Options{Streams: []Stream{{
+		Stream:        "events",
+		Group:         "g",
+		StartID:       "0",
+		Block:         10 * time.Millisecond,
+		MinIdle:       10 * time.Millisecond,
+		ClaimInterval: 20 * time.Millisecond,
+		MaxDeliveries: 2,
+	}}})
+
+	if err := c.HandleStream("", "missing", nil); err == nil {
+		t.Fatal("HandleStream on undeclared stream should fail")
+	}
+
+	done := make(chan string, 10)
+	err := c.HandleStream("", "events", func(ctx context.Context, msg *StreamMessage) error {
+		if msg.Values["poison"] != nil {
+			return errors.New("poison")
+		}
+		done <- msg.ID
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.HandleStream("", "events", nil); err == nil {
+		t.Fatal("duplicate HandleStream should fail")
+	}
+
+	good, _ := client.XAdd(ctx, &redis.XAddArgs{Stream: "events", Values: map[string]interface{}{"ok": 1}}).Result()
+	client.XAdd(ctx, &redis.XAddArgs{Stream: "events", Values: map[string]interface{}{"poison": 1}})
+	if err = c.startStreams(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case id := <-done:
+		if id != good {
+			t.Fatalf("handled %s, want %s", id, good)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message not handled")
+	}
+
+	// 未配置死信 stream 的毒消息在超过最大投递次数后被确认丢弃
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		pending, err := client.XPending(ctx, "events", "g").Result()
+		if err == nil && pending.Count == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pending = %+v, %v", pending, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err = c.stopStreams(stopCtx); err != nil {
+		t.Fatal(err)
+	}
+}